@@ -9,6 +9,7 @@ import (
 	"telegram-communication-bot/internal/config"
 	"telegram-communication-bot/internal/database"
 	"telegram-communication-bot/internal/handlers"
+	"telegram-communication-bot/internal/i18n"
 	"telegram-communication-bot/internal/services"
 
 	tgbot "github.com/go-telegram/bot"
@@ -25,6 +26,20 @@ type Bot struct {
 	ForumService   *services.ForumService
 	RateLimiter    *services.RateLimiter
 	CaptchaService *services.CaptchaService
+	Transcripts    *services.TranscriptService
+	Assignments    *services.AssignmentService
+	Collisions     *services.CollisionDetector
+	SLAService     *services.SLAService
+	BusinessHours  *services.BusinessHours
+	AutoReplies    *services.AutoReplyService
+	Menu           *services.MenuService
+	CSAT           *services.CSATService
+	Catalog        *i18n.Catalog
+	Broadcasts     *services.BroadcastDrafts
+	ContentFilter  *services.ContentFilter
+	Moderation     *services.ModerationQueue
+	FloodGuard     *services.FloodGuard
+	Policy         *services.PolicyEngine
 	handlers       *handlers.Handlers
 }
 
@@ -34,10 +49,39 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
+	catalog, err := i18n.NewCatalog(cfg.LocalesDir, cfg.DefaultLocale, cfg.AdminLocale)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load locales: %w", err)
+	}
+
 	scheduler := cron.New(cron.WithSeconds())
-	messageService := services.NewMessageService(db)
-	rateLimiter := services.NewRateLimiter(cfg.MessageInterval)
-	captchaService := services.NewCaptchaService()
+	messageService := services.NewMessageService(db, catalog)
+	rateLimiter := services.NewRateLimiter(cfg.MessageInterval, catalog)
+	captchaService := services.NewCaptchaService(catalog)
+	transcripts := services.NewTranscriptService(db, cfg.TranscriptRetentionDays, catalog)
+	assignments := services.NewAssignmentService(cfg, db)
+	collisions := services.NewCollisionDetector(cfg.CollisionWindow, cfg.SoftLock)
+	autoReplies := services.NewAutoReplyService(db)
+	csat := services.NewCSATService(cfg, db)
+	broadcasts := services.NewBroadcastDrafts()
+	contentFilter := services.NewContentFilter(db)
+	floodGuard := services.NewFloodGuard(cfg.FloodWindow, cfg.FloodThreshold)
+	businessHours, err := services.NewBusinessHours(cfg, db, catalog)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	menu, err := services.NewMenuService(cfg.MenuFile, catalog)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	policy, err := services.NewPolicyEngine(cfg)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	b := &Bot{
 		Config:         cfg,
@@ -46,6 +90,18 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 		MessageService: messageService,
 		RateLimiter:    rateLimiter,
 		CaptchaService: captchaService,
+		Transcripts:    transcripts,
+		Assignments:    assignments,
+		Collisions:     collisions,
+		BusinessHours:  businessHours,
+		AutoReplies:    autoReplies,
+		Menu:           menu,
+		CSAT:           csat,
+		Catalog:        catalog,
+		Broadcasts:     broadcasts,
+		ContentFilter:  contentFilter,
+		FloodGuard:     floodGuard,
+		Policy:         policy,
 	}
 
 	opts := []tgbot.Option{
@@ -66,7 +122,13 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 	forumService := services.NewForumService(tg, cfg, db)
 	b.ForumService = forumService
 
-	h := handlers.NewHandlers(tg, cfg, db, messageService, forumService, rateLimiter, captchaService)
+	slaService := services.NewSLAService(tg, cfg, db, catalog)
+	b.SLAService = slaService
+
+	moderation := services.NewModerationQueue(tg, cfg, db, catalog, forumService, messageService)
+	b.Moderation = moderation
+
+	h := handlers.NewHandlers(tg, cfg, db, messageService, forumService, rateLimiter, captchaService, transcripts, assignments, collisions, slaService, businessHours, autoReplies, menu, csat, catalog, broadcasts, contentFilter, moderation, floodGuard, policy)
 	b.handlers = h
 
 	b.setupScheduledTasks()
@@ -154,7 +216,42 @@ func (b *Bot) setupScheduledTasks() {
 		}
 		b.RateLimiter.CleanupStaleEntries()
 		b.CaptchaService.CleanupExpired()
+		b.Collisions.CleanupStaleEntries()
+		b.FloodGuard.CleanupStaleEntries()
+		if err := b.Transcripts.CleanupExpired(); err != nil {
+			log.Printf("Error cleaning up old transcript messages: %v", err)
+		}
 	})
 
+	b.Scheduler.AddFunc("@every 1m", func() {
+		b.handlers.LiftExpiredMutes(context.Background())
+	})
+
+	if b.SLAService.IsEnabled() {
+		b.Scheduler.AddFunc("@every 1m", func() {
+			b.SLAService.CheckBreaches(context.Background())
+		})
+	}
+
+	if b.Config.ReconcileIntervalMinutes > 0 {
+		spec := fmt.Sprintf("@every %dm", b.Config.ReconcileIntervalMinutes)
+		b.Scheduler.AddFunc(spec, func() {
+			ctx := context.Background()
+			report, err := b.ForumService.Reconcile(ctx)
+			if err != nil {
+				log.Printf("Error reconciling topics: %v", err)
+				return
+			}
+			if !report.HasIssues() {
+				return
+			}
+			b.tg.SendMessage(ctx, &tgbot.SendMessageParams{
+				ChatID:    b.Config.AdminGroupID,
+				Text:      report.Format(b.Catalog),
+				ParseMode: models.ParseModeHTML,
+			})
+		})
+	}
+
 	log.Println("Scheduled tasks configured")
 }
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +17,12 @@ type Config struct {
 	BotToken    string
 	AppName     string
 	WelcomeMessage string
+	MenuFile    string
+
+	// Localization Settings
+	DefaultLocale string // fallback for users whose language has no locale file
+	AdminLocale   string
+	LocalesDir    string // optional directory of *.json locale overrides
 
 	// Admin Configuration
 	AdminGroupID  int64
@@ -24,9 +32,36 @@ type Config struct {
 	DeleteTopicAsForeverBan      bool
 	DeleteUserMessageOnClearCmd  bool
 	MessageInterval              int
+	ReconcileIntervalMinutes     int
+	AutoAssign                   bool
+	CollisionWindow              int
+	SoftLock                     bool
+	CSATEnabled                  bool
+
+	// Flood Settings
+	FloodWindow      int // seconds, 0 = no duplicate or flood detection
+	FloodThreshold   int // messages per window before an automatic mute, 0 = off
+	FloodMuteMinutes int
+
+	// New User Restrictions
+	NewUserRestrictions []string // message features held for review until the user is trusted
+	NewUserMinMessages  int      // messages relayed before restrictions lift
+	NewUserMinAgeHours  int      // hours since first contact before restrictions lift, 0 = never
+
+	// SLA Settings
+	SLAFirstResponseMinutes int
+	SLANextResponseMinutes  int
+	SLAAlertChatID          int64
+
+	// Business Hours Settings
+	BusinessHours     string // e.g. "mon-fri 09:00-18:00; sat 10:00-14:00", empty = always open
+	BusinessTimezone  *time.Location
+	BusinessHolidays  []string // YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD
+	OutOfHoursMessage string
 
 	// Database Settings
-	DatabasePath string
+	DatabasePath            string
+	TranscriptRetentionDays int
 
 	// Server Settings
 	Port       int
@@ -56,7 +91,14 @@ func LoadConfig() (*Config, error) {
 
 	// Load app settings
 	config.AppName = getEnvWithDefault("APP_NAME", "TelegramCommunicationBot")
-	config.WelcomeMessage = getEnvWithDefault("WELCOME_MESSAGE", "欢迎使用我们的客服机器人！请发送您的问题，我们的客服人员将尽快回复您。")
+	config.WelcomeMessage = os.Getenv("WELCOME_MESSAGE") // empty = localized default
+
+	config.MenuFile = os.Getenv("MENU_FILE")
+
+	// Localization settings
+	config.DefaultLocale = getEnvWithDefault("DEFAULT_LOCALE", "zh")
+	config.AdminLocale = getEnvWithDefault("ADMIN_LOCALE", config.DefaultLocale)
+	config.LocalesDir = os.Getenv("LOCALES_DIR")
 
 	// Load admin configuration
 	adminGroupIDStr := os.Getenv("ADMIN_GROUP_ID")
@@ -86,9 +128,60 @@ func LoadConfig() (*Config, error) {
 	config.DeleteTopicAsForeverBan = getBoolEnv("DELETE_TOPIC_AS_FOREVER_BAN", false)
 	config.DeleteUserMessageOnClearCmd = getBoolEnv("DELETE_USER_MESSAGE_ON_CLEAR_CMD", false)
 	config.MessageInterval = getIntEnv("MESSAGE_INTERVAL", 5)
+	config.ReconcileIntervalMinutes = getIntEnv("RECONCILE_INTERVAL_MINUTES", 360)
+	config.AutoAssign = getBoolEnv("AUTO_ASSIGN", false)
+	config.CollisionWindow = getIntEnv("COLLISION_WINDOW", 120)
+	config.SoftLock = getBoolEnv("SOFT_LOCK", false)
+	config.CSATEnabled = getBoolEnv("CSAT_ENABLED", false)
+
+	// Flood settings
+	config.FloodWindow = getIntEnv("FLOOD_WINDOW", 0)
+	config.FloodThreshold = getIntEnv("FLOOD_THRESHOLD", 20)
+	config.FloodMuteMinutes = getIntEnv("FLOOD_MUTE_MINUTES", 30)
+
+	// New user restrictions
+	if restrictionsStr := os.Getenv("NEW_USER_RESTRICTIONS"); restrictionsStr != "" {
+		for _, feature := range strings.Split(restrictionsStr, ",") {
+			if feature = strings.ToLower(strings.TrimSpace(feature)); feature != "" {
+				config.NewUserRestrictions = append(config.NewUserRestrictions, feature)
+			}
+		}
+	}
+	config.NewUserMinMessages = getIntEnv("NEW_USER_MIN_MESSAGES", 5)
+	config.NewUserMinAgeHours = getIntEnv("NEW_USER_MIN_AGE_HOURS", 24)
+
+	// SLA settings
+	config.SLAFirstResponseMinutes = getIntEnv("SLA_FIRST_RESPONSE_MINUTES", 0)
+	config.SLANextResponseMinutes = getIntEnv("SLA_NEXT_RESPONSE_MINUTES", 0)
+	config.SLAAlertChatID = config.AdminGroupID
+	if alertChatStr := os.Getenv("SLA_ALERT_CHAT_ID"); alertChatStr != "" {
+		alertChatID, err := strconv.ParseInt(alertChatStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLA_ALERT_CHAT_ID: %w", err)
+		}
+		config.SLAAlertChatID = alertChatID
+	}
+
+	// Business hours settings
+	config.BusinessHours = strings.TrimSpace(os.Getenv("BUSINESS_HOURS"))
+	timezone := getEnvWithDefault("BUSINESS_TIMEZONE", "Local")
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUSINESS_TIMEZONE: %w", err)
+	}
+	config.BusinessTimezone = location
+	if holidaysStr := os.Getenv("BUSINESS_HOLIDAYS"); holidaysStr != "" {
+		for _, holiday := range strings.Split(holidaysStr, ",") {
+			if holiday = strings.TrimSpace(holiday); holiday != "" {
+				config.BusinessHolidays = append(config.BusinessHolidays, holiday)
+			}
+		}
+	}
+	config.OutOfHoursMessage = os.Getenv("OUT_OF_HOURS_MESSAGE")
 
 	// Load database settings
 	config.DatabasePath = getEnvWithDefault("DATABASE_PATH", "./data/bot.db")
+	config.TranscriptRetentionDays = getIntEnv("TRANSCRIPT_RETENTION_DAYS", 0)
 
 	// Load server settings
 	config.Port = getIntEnv("PORT", 8090)
@@ -136,6 +229,34 @@ func (c *Config) ValidateConfig() error {
 		return fmt.Errorf("MESSAGE_INTERVAL must be non-negative")
 	}
 
+	if c.SLAFirstResponseMinutes < 0 || c.SLANextResponseMinutes < 0 {
+		return fmt.Errorf("SLA thresholds must be non-negative")
+	}
+
+	if c.CollisionWindow < 0 {
+		return fmt.Errorf("COLLISION_WINDOW must be non-negative")
+	}
+
+	if c.FloodWindow < 0 || c.FloodThreshold < 0 {
+		return fmt.Errorf("FLOOD_WINDOW and FLOOD_THRESHOLD must be non-negative")
+	}
+
+	if c.FloodThreshold > 0 && c.FloodMuteMinutes <= 0 {
+		return fmt.Errorf("FLOOD_MUTE_MINUTES must be positive when FLOOD_THRESHOLD is set")
+	}
+
+	if c.NewUserMinMessages < 0 || c.NewUserMinAgeHours < 0 {
+		return fmt.Errorf("NEW_USER_MIN_MESSAGES and NEW_USER_MIN_AGE_HOURS must be non-negative")
+	}
+
+	if c.TranscriptRetentionDays < 0 {
+		return fmt.Errorf("TRANSCRIPT_RETENTION_DAYS must be non-negative")
+	}
+
+	if c.ReconcileIntervalMinutes < 0 {
+		return fmt.Errorf("RECONCILE_INTERVAL_MINUTES must be non-negative")
+	}
+
 	if c.Port <= 0 || c.Port > 65535 {
 		return fmt.Errorf("PORT must be between 1 and 65535")
 	}
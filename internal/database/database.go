@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"telegram-communication-bot/internal/models"
 	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 	_ "modernc.org/sqlite"
 )
@@ -62,9 +65,49 @@ func NewDatabase(databasePath string, debug bool) (*DB, error) {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if err := setupTranscriptSearch(db); err != nil {
+		return nil, fmt.Errorf("failed to set up full-text search: %w", err)
+	}
+
 	return &DB{DB: db}, nil
 }
 
+// setupTranscriptSearch creates the FTS5 index over transcript text and the
+// triggers that keep it in sync. The trigram tokenizer is used so that CJK
+// text, which has no word separators, can be searched by substring.
+func setupTranscriptSearch(db *gorm.DB) error {
+	var exists int64
+	if err := db.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'transcript_fts'").Scan(&exists).Error; err != nil {
+		return err
+	}
+
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS transcript_fts USING fts5(
+			text, content='transcript_messages', content_rowid='id', tokenize='trigram')`,
+		`CREATE TRIGGER IF NOT EXISTS transcript_fts_ai AFTER INSERT ON transcript_messages BEGIN
+			INSERT INTO transcript_fts(rowid, text) VALUES (new.id, new.text);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS transcript_fts_ad AFTER DELETE ON transcript_messages BEGIN
+			INSERT INTO transcript_fts(transcript_fts, rowid, text) VALUES ('delete', old.id, old.text);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS transcript_fts_au AFTER UPDATE ON transcript_messages BEGIN
+			INSERT INTO transcript_fts(transcript_fts, rowid, text) VALUES ('delete', old.id, old.text);
+			INSERT INTO transcript_fts(rowid, text) VALUES (new.id, new.text);
+		END`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	// Index transcripts stored before the search index existed
+	if exists == 0 {
+		return db.Exec("INSERT INTO transcript_fts(transcript_fts) VALUES ('rebuild')").Error
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	sqlDB, err := db.DB.DB()
@@ -95,6 +138,48 @@ func (db *DB) GetAllUsers() ([]models.User, error) {
 	return users, err
 }
 
+// GetUserByUsername looks a user up by Telegram username (without @, case-insensitive)
+func (db *DB) GetUserByUsername(username string) (*models.User, error) {
+	var user models.User
+	err := db.DB.Where("LOWER(username) = LOWER(?)", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// likeEscaper escapes LIKE wildcards so user input matches literally; queries
+// using it must declare ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// SearchUsers matches users by exact ID or partial username / name
+func (db *DB) SearchUsers(query string, limit int) ([]models.User, error) {
+	var users []models.User
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+	q := db.DB.Where(`username LIKE ? ESCAPE '\' OR first_name LIKE ? ESCAPE '\' OR last_name LIKE ? ESCAPE '\' OR (first_name || ' ' || last_name) LIKE ? ESCAPE '\'`,
+		pattern, pattern, pattern, pattern)
+	if userID, err := strconv.ParseInt(query, 10, 64); err == nil {
+		q = q.Or("user_id = ?", userID)
+	}
+	err := q.Order("last_active_at DESC").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+// GetUsersWithThreads returns all users that currently have a forum topic
+func (db *DB) GetUsersWithThreads() ([]models.User, error) {
+	var users []models.User
+	err := db.DB.Where("message_thread_id <> 0").Find(&users).Error
+	return users, err
+}
+
+// ClearUserThread detaches a user from their topic without touching ForumStatus
+func (db *DB) ClearUserThread(userID int64) error {
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"message_thread_id": 0,
+		"card_message_id":   0,
+	}).Error
+}
+
 // MessageMap operations
 func (db *DB) CreateMessageMap(messageMap *models.MessageMap) error {
 	messageMap.CreatedAt = time.Now()
@@ -119,6 +204,13 @@ func (db *DB) GetMessageMapByGroupMessage(groupChatMessageID int) (*models.Messa
 	return &messageMap, nil
 }
 
+// CountMessageMaps returns the number of relayed messages recorded for a user
+func (db *DB) CountMessageMaps(userID int64) (int64, error) {
+	var count int64
+	err := db.DB.Model(&models.MessageMap{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
 // MediaGroupMessage operations
 func (db *DB) CreateMediaGroupMessage(msg *models.MediaGroupMessage) error {
 	msg.CreatedAt = time.Now()
@@ -135,10 +227,18 @@ func (db *DB) DeleteMediaGroupMessages(mediaGroupID string) error {
 	return db.DB.Where("media_group_id = ?", mediaGroupID).Delete(&models.MediaGroupMessage{}).Error
 }
 
-// ForumStatus operations
+// ForumStatus operations (upserted by message thread ID)
 func (db *DB) CreateOrUpdateForumStatus(status *models.ForumStatus) error {
 	status.UpdatedAt = time.Now()
-	return db.DB.Save(status).Error
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_thread_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "updated_at"}),
+	}).Create(status).Error
+}
+
+// SetForumTopicName records the current name of a forum topic
+func (db *DB) SetForumTopicName(messageThreadID int, name string) error {
+	return db.DB.Model(&models.ForumStatus{}).Where("message_thread_id = ?", messageThreadID).Update("name", name).Error
 }
 
 func (db *DB) GetForumStatus(messageThreadID int) (*models.ForumStatus, error) {
@@ -150,6 +250,17 @@ func (db *DB) GetForumStatus(messageThreadID int) (*models.ForumStatus, error) {
 	return &status, nil
 }
 
+// GetAllForumStatuses returns every tracked forum topic
+func (db *DB) GetAllForumStatuses() ([]models.ForumStatus, error) {
+	var statuses []models.ForumStatus
+	err := db.DB.Find(&statuses).Error
+	return statuses, err
+}
+
+// DeleteForumStatus removes the status row for a forum topic
+func (db *DB) DeleteForumStatus(messageThreadID int) error {
+	return db.DB.Where("message_thread_id = ?", messageThreadID).Delete(&models.ForumStatus{}).Error
+}
 
 // UserMessage operations for rate limiting
 func (db *DB) CreateUserMessage(msg *models.UserMessage) error {
@@ -166,13 +277,475 @@ func (db *DB) CleanupOldUserMessages(before time.Time) error {
 	return db.DB.Where("sent_at < ?", before).Delete(&models.UserMessage{}).Error
 }
 
+// TranscriptMessage operations
+func (db *DB) CreateTranscriptMessage(msg *models.TranscriptMessage) error {
+	msg.CreatedAt = time.Now()
+	return db.DB.Create(msg).Error
+}
+
+// GetTranscript returns a user's stored messages in chronological order
+func (db *DB) GetTranscript(userID int64) ([]models.TranscriptMessage, error) {
+	var messages []models.TranscriptMessage
+	err := db.DB.Where("user_id = ?", userID).Order("sent_at, id").Find(&messages).Error
+	return messages, err
+}
+
+func (db *DB) CleanupOldTranscriptMessages(before time.Time) error {
+	return db.DB.Where("sent_at < ?", before).Delete(&models.TranscriptMessage{}).Error
+}
+
+// TranscriptSearchFilter narrows a transcript search. Zero values are ignored.
+type TranscriptSearchFilter struct {
+	UserID    int64
+	Since     time.Time
+	Until     time.Time
+	Direction string
+	Limit     int
+}
+
+// TranscriptSearchResult is a matching transcript message with a highlighted
+// excerpt. Matches in Snippet are wrapped in \x02 and \x03.
+type TranscriptSearchResult struct {
+	models.TranscriptMessage
+	Snippet string
+}
+
+// SearchTranscripts runs an FTS5 MATCH query over transcript text, ranked by bm25
+func (db *DB) SearchTranscripts(match string, filter TranscriptSearchFilter) ([]TranscriptSearchResult, error) {
+	query := db.DB.Table("transcript_fts").
+		Select("transcript_messages.*, snippet(transcript_fts, 0, char(2), char(3), '…', 16) AS snippet").
+		Joins("JOIN transcript_messages ON transcript_messages.id = transcript_fts.rowid").
+		Where("transcript_fts MATCH ?", match)
+	query = applyTranscriptFilter(query, filter)
+
+	var results []TranscriptSearchResult
+	err := query.Order("rank").Find(&results).Error
+	return results, err
+}
+
+// SearchTranscriptsLike is a substring search for terms too short for the
+// trigram index. Results are ordered newest first and carry no snippet.
+func (db *DB) SearchTranscriptsLike(terms []string, filter TranscriptSearchFilter) ([]TranscriptSearchResult, error) {
+	query := db.DB.Table("transcript_messages").Select("transcript_messages.*")
+	for _, term := range terms {
+		query = query.Where(`transcript_messages.text LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(term)+"%")
+	}
+	query = applyTranscriptFilter(query, filter)
+
+	var results []TranscriptSearchResult
+	err := query.Order("transcript_messages.sent_at DESC").Find(&results).Error
+	return results, err
+}
+
+func applyTranscriptFilter(query *gorm.DB, filter TranscriptSearchFilter) *gorm.DB {
+	if filter.UserID != 0 {
+		query = query.Where("transcript_messages.user_id = ?", filter.UserID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("transcript_messages.sent_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("transcript_messages.sent_at < ?", filter.Until)
+	}
+	if filter.Direction != "" {
+		query = query.Where("transcript_messages.direction = ?", filter.Direction)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return query
+}
+
+// UserTag operations
+func (db *DB) AddUserTag(userID int64, tag string) error {
+	return db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserTag{
+		UserID:    userID,
+		Tag:       tag,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+func (db *DB) RemoveUserTag(userID int64, tag string) error {
+	return db.DB.Where("user_id = ? AND tag = ?", userID, tag).Delete(&models.UserTag{}).Error
+}
+
+func (db *DB) GetUserTags(userID int64) ([]string, error) {
+	var tags []string
+	err := db.DB.Model(&models.UserTag{}).Where("user_id = ?", userID).Order("tag").Pluck("tag", &tags).Error
+	return tags, err
+}
+
+// GetUsersByTags returns users carrying every one of the given tags
+func (db *DB) GetUsersByTags(tags []string) ([]models.User, error) {
+	var users []models.User
+	err := db.DB.Model(&models.User{}).
+		Joins("JOIN user_tags ON user_tags.user_id = users.user_id").
+		Where("user_tags.tag IN ?", tags).
+		Group("users.user_id").
+		Having("COUNT(DISTINCT user_tags.tag) = ?", len(tags)).
+		Find(&users).Error
+	return users, err
+}
+
+// UserAttribute operations
+func (db *DB) SetUserAttribute(userID int64, key string, value string) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&models.UserAttribute{
+		UserID:    userID,
+		Key:       key,
+		Value:     value,
+		UpdatedAt: time.Now(),
+	}).Error
+}
+
+func (db *DB) DeleteUserAttribute(userID int64, key string) error {
+	return db.DB.Where("user_id = ? AND key = ?", userID, key).Delete(&models.UserAttribute{}).Error
+}
+
+func (db *DB) GetUserAttributes(userID int64) ([]models.UserAttribute, error) {
+	var attributes []models.UserAttribute
+	err := db.DB.Where("user_id = ?", userID).Order("key").Find(&attributes).Error
+	return attributes, err
+}
+
+// Agent operations
+
+// UpsertAgentProfile records an agent's Telegram profile, keeping their availability
+func (db *DB) UpsertAgentProfile(agent *models.Agent) error {
+	agent.UpdatedAt = time.Now()
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "agent_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"first_name", "last_name", "username", "updated_at"}),
+	}).Create(agent).Error
+}
+
+func (db *DB) GetAgent(agentID int64) (*models.Agent, error) {
+	var agent models.Agent
+	err := db.DB.First(&agent, agentID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+func (db *DB) GetAgentByUsername(username string) (*models.Agent, error) {
+	var agent models.Agent
+	err := db.DB.Where("LOWER(username) = LOWER(?)", username).First(&agent).Error
+	if err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// GetOnlineAgents returns online agents among the given IDs, least recently assigned first
+func (db *DB) GetOnlineAgents(agentIDs []int64) ([]models.Agent, error) {
+	var agents []models.Agent
+	err := db.DB.Where("agent_id IN ? AND online = ?", agentIDs, true).
+		Order("last_assigned_at, agent_id").Find(&agents).Error
+	return agents, err
+}
+
+func (db *DB) SetAgentOnline(agentID int64, online bool) error {
+	return db.DB.Model(&models.Agent{}).Where("agent_id = ?", agentID).Update("online", online).Error
+}
+
+func (db *DB) TouchAgentAssignment(agentID int64) error {
+	return db.DB.Model(&models.Agent{}).Where("agent_id = ?", agentID).Update("last_assigned_at", time.Now()).Error
+}
+
+func (db *DB) SetUserAssignedAgent(userID int64, agentID int64) error {
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Update("assigned_agent_id", agentID).Error
+}
+
+// GetOpenAssignedUsers returns users assigned to an agent whose topic is still open
+func (db *DB) GetOpenAssignedUsers(agentID int64) ([]models.User, error) {
+	var users []models.User
+	err := db.DB.Model(&models.User{}).
+		Joins("JOIN forum_statuses ON forum_statuses.message_thread_id = users.message_thread_id").
+		Where("users.assigned_agent_id = ? AND users.message_thread_id <> 0 AND forum_statuses.status = ?", agentID, "opened").
+		Order("users.last_active_at DESC").
+		Find(&users).Error
+	return users, err
+}
+
+// ConversationSLA operations
+func (db *DB) GetConversationSLA(userID int64) (*models.ConversationSLA, error) {
+	var sla models.ConversationSLA
+	err := db.DB.First(&sla, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sla, nil
+}
+
+func (db *DB) SaveConversationSLA(sla *models.ConversationSLA) error {
+	sla.UpdatedAt = time.Now()
+	return db.DB.Save(sla).Error
+}
+
+// GetWaitingConversations returns unalerted conversations waiting since before the cutoff
+func (db *DB) GetWaitingConversations(firstResponded bool, waitingBefore time.Time) ([]models.ConversationSLA, error) {
+	var slas []models.ConversationSLA
+	err := db.DB.Where("first_responded = ? AND alerted = ? AND awaiting_since > ? AND awaiting_since < ?",
+		firstResponded, false, time.Time{}, waitingBefore).Find(&slas).Error
+	return slas, err
+}
+
+func (db *DB) CreateResponseTime(rt *models.ResponseTime) error {
+	return db.DB.Create(rt).Error
+}
+
+// AverageResponseSeconds returns the mean wait for a response kind since the given time
+func (db *DB) AverageResponseSeconds(kind string, since time.Time) (float64, int64, error) {
+	var result struct {
+		Avg   float64
+		Count int64
+	}
+	err := db.DB.Model(&models.ResponseTime{}).
+		Select("COALESCE(AVG(wait_seconds), 0) AS avg, COUNT(*) AS count").
+		Where("kind = ? AND responded_at >= ?", kind, since).
+		Scan(&result).Error
+	return result.Avg, result.Count, err
+}
+
+// AutoReplyRule operations
+func (db *DB) CreateAutoReplyRule(rule *models.AutoReplyRule) error {
+	return db.DB.Create(rule).Error
+}
+
+func (db *DB) GetAutoReplyRules() ([]models.AutoReplyRule, error) {
+	var rules []models.AutoReplyRule
+	err := db.DB.Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (db *DB) GetAutoReplyRule(id uint) (*models.AutoReplyRule, error) {
+	var rule models.AutoReplyRule
+	err := db.DB.First(&rule, id).Error
+	return &rule, err
+}
+
+// DeleteAutoReplyRule removes a rule, returning gorm.ErrRecordNotFound if it doesn't exist
+func (db *DB) DeleteAutoReplyRule(id uint) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.AutoReplyRule{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("rule_id = ?", id).Delete(&models.AutoReplyVariant{}).Error
+	})
+}
+
+// SetAutoReplyVariant creates or replaces the reply of a rule for one locale
+func (db *DB) SetAutoReplyVariant(variant *models.AutoReplyVariant) error {
+	variant.UpdatedAt = time.Now()
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "rule_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reply_text", "source_chat_id", "source_message_id", "updated_at"}),
+	}).Create(variant).Error
+}
+
+func (db *DB) GetAutoReplyVariants() ([]models.AutoReplyVariant, error) {
+	var variants []models.AutoReplyVariant
+	err := db.DB.Order("rule_id, locale").Find(&variants).Error
+	return variants, err
+}
+
+func (db *DB) IncrementAutoReplyHits(id uint) error {
+	return db.DB.Model(&models.AutoReplyRule{}).Where("id = ?", id).
+		UpdateColumn("hit_count", gorm.Expr("hit_count + ?", 1)).Error
+}
+
+// FilterRule operations
+func (db *DB) CreateFilterRule(rule *models.FilterRule) error {
+	return db.DB.Create(rule).Error
+}
+
+func (db *DB) GetFilterRules() ([]models.FilterRule, error) {
+	var rules []models.FilterRule
+	err := db.DB.Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// DeleteFilterRule removes a rule, returning gorm.ErrRecordNotFound if it doesn't exist
+func (db *DB) DeleteFilterRule(id uint) error {
+	result := db.DB.Delete(&models.FilterRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RecordFilterHit logs a filtered message and bumps the rule's counter
+func (db *DB) RecordFilterHit(hit *models.FilterHit) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(hit).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.FilterRule{}).Where("id = ?", hit.RuleID).
+			UpdateColumn("hit_count", gorm.Expr("hit_count + ?", 1)).Error
+	})
+}
+
+// CountFilterHitsByAction returns the number of filter hits per action since the given time
+func (db *DB) CountFilterHitsByAction(since time.Time) (map[string]int64, error) {
+	var rows []struct {
+		Action string
+		Count  int64
+	}
+	err := db.DB.Model(&models.FilterHit{}).
+		Select("action, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group("action").
+		Scan(&rows).Error
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Action] = row.Count
+	}
+	return counts, err
+}
+
+// Setting operations
+func (db *DB) GetSetting(key string) (string, error) {
+	var setting models.Setting
+	err := db.DB.Where("key = ?", key).First(&setting).Error
+	return setting.Value, err
+}
+
+func (db *DB) SetSetting(key string, value string) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&models.Setting{
+		Key:       key,
+		Value:     value,
+		UpdatedAt: time.Now(),
+	}).Error
+}
+
+// HeldMessage operations
+func (db *DB) SaveHeldMessage(held *models.HeldMessage) error {
+	return db.DB.Save(held).Error
+}
+
+func (db *DB) GetHeldMessage(id uint) (*models.HeldMessage, error) {
+	var held models.HeldMessage
+	err := db.DB.First(&held, id).Error
+	return &held, err
+}
+
+// ResolveHeldMessage moves a pending held message to its final status.
+// Returns gorm.ErrRecordNotFound if it was already reviewed.
+func (db *DB) ResolveHeldMessage(id uint, status string, reviewedBy int64) error {
+	result := db.DB.Model(&models.HeldMessage{}).
+		Where("id = ? AND status = ?", id, models.HeldStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewedBy,
+			"reviewed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (db *DB) CountPendingHeldMessages() (int64, error) {
+	var count int64
+	err := db.DB.Model(&models.HeldMessage{}).Where("status = ?", models.HeldStatusPending).Count(&count).Error
+	return count, err
+}
+
+// CSATSurvey operations
+func (db *DB) SaveCSATSurvey(survey *models.CSATSurvey) error {
+	return db.DB.Save(survey).Error
+}
+
+func (db *DB) GetCSATSurvey(id uint) (*models.CSATSurvey, error) {
+	var survey models.CSATSurvey
+	err := db.DB.First(&survey, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &survey, nil
+}
+
+// GetCSATSurveyByCommentMessage finds the survey whose comment prompt the user replied to
+func (db *DB) GetCSATSurveyByCommentMessage(userID int64, messageID int) (*models.CSATSurvey, error) {
+	var survey models.CSATSurvey
+	err := db.DB.Where("user_id = ? AND comment_message_id = ?", userID, messageID).First(&survey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &survey, nil
+}
+
+// GetLastOutboundSender returns the agent who most recently replied to the user
+func (db *DB) GetLastOutboundSender(userID int64) (int64, error) {
+	var message models.TranscriptMessage
+	err := db.DB.Where("user_id = ? AND direction = ? AND sender_id <> 0", userID, models.DirectionOutbound).
+		Order("sent_at DESC").First(&message).Error
+	if err != nil {
+		return 0, err
+	}
+	return message.SenderID, nil
+}
+
+// CSATAgentSummary is the average rating of one agent
+type CSATAgentSummary struct {
+	AgentID int64
+	Avg     float64
+	Count   int64
+}
+
+// SummarizeCSAT returns the overall average rating since the given time and a per-agent breakdown
+func (db *DB) SummarizeCSAT(since time.Time) (float64, int64, []CSATAgentSummary, error) {
+	var total struct {
+		Avg   float64
+		Count int64
+	}
+	err := db.DB.Model(&models.CSATSurvey{}).
+		Select("COALESCE(AVG(score), 0) AS avg, COUNT(*) AS count").
+		Where("score > 0 AND rated_at >= ?", since).
+		Scan(&total).Error
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	var agents []CSATAgentSummary
+	err = db.DB.Model(&models.CSATSurvey{}).
+		Select("agent_id, AVG(score) AS avg, COUNT(*) AS count").
+		Where("score > 0 AND rated_at >= ? AND agent_id <> 0", since).
+		Group("agent_id").Order("avg DESC").
+		Scan(&agents).Error
+	return total.Avg, total.Count, agents, err
+}
+
 // BanStatus operations
+
+// CreateOrUpdateBanStatus records a regular ban or unban. Only the ban columns
+// are written, so a shadow ban on the same user is left as is.
 func (db *DB) CreateOrUpdateBanStatus(banStatus *models.BanStatus) error {
 	banStatus.UpdatedAt = time.Now()
 	if banStatus.IsBanned {
 		banStatus.BannedAt = time.Now()
 	}
-	return db.DB.Save(banStatus).Error
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"is_banned", "banned_at", "reason", "updated_at"}),
+	}).Create(banStatus).Error
 }
 
 func (db *DB) GetBanStatus(userID int64) (*models.BanStatus, error) {
@@ -193,6 +766,74 @@ func (db *DB) IsUserBanned(userID int64) bool {
 	return banStatus.IsBanned
 }
 
+// SetShadowBan turns a user's shadow ban on or off. Only the shadow_banned
+// column is written, so a regular ban and its reason are left as is.
+func (db *DB) SetShadowBan(userID int64, shadowBanned bool) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"shadow_banned"}),
+	}).Create(&models.BanStatus{
+		UserID:       userID,
+		ShadowBanned: shadowBanned,
+	}).Error
+}
+
+func (db *DB) IsUserShadowBanned(userID int64) bool {
+	var banStatus models.BanStatus
+	err := db.DB.First(&banStatus, userID).Error
+	if err != nil {
+		return false
+	}
+	return banStatus.ShadowBanned
+}
+
+// MuteStatus operations
+func (db *DB) MuteUser(mute *models.MuteStatus) error {
+	return db.DB.Save(mute).Error
+}
+
+// UnmuteUser lifts a user's mute and returns it.
+// Returns gorm.ErrRecordNotFound if the user was not muted.
+func (db *DB) UnmuteUser(userID int64) (*models.MuteStatus, error) {
+	var mute models.MuteStatus
+	if err := db.DB.First(&mute, userID).Error; err != nil {
+		return nil, err
+	}
+	if err := db.DB.Delete(&mute).Error; err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
+func (db *DB) MarkMuteNotified(userID int64) error {
+	return db.DB.Model(&models.MuteStatus{}).Where("user_id = ?", userID).Update("notified", true).Error
+}
+
+// ExpireMutes removes mutes that ran out before now and returns them
+func (db *DB) ExpireMutes(now time.Time) ([]models.MuteStatus, error) {
+	var mutes []models.MuteStatus
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("muted_until <= ?", now).Find(&mutes).Error; err != nil {
+			return err
+		}
+		if len(mutes) == 0 {
+			return nil
+		}
+		return tx.Delete(&mutes).Error
+	})
+	return mutes, err
+}
+
+// GetActiveMute returns the user's mute if it has not expired yet
+func (db *DB) GetActiveMute(userID int64) (*models.MuteStatus, error) {
+	var mute models.MuteStatus
+	err := db.DB.Where("user_id = ? AND muted_until > ?", userID, time.Now()).First(&mute).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
 // CountUsers returns the total number of users
 func (db *DB) CountUsers() (int64, error) {
 	var count int64
@@ -223,7 +864,49 @@ func (db *DB) IsUserVerified(userID int64) bool {
 	return user.Verified
 }
 
+// IncrementUserMessageCount counts a message that was relayed to agents
+func (db *DB) IncrementUserMessageCount(userID int64) error {
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).
+		Update("message_count", gorm.Expr("message_count + ?", 1)).Error
+}
+
 // SetUserVerified updates the verified status for a user
 func (db *DB) SetUserVerified(userID int64, verified bool) error {
 	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Update("verified", verified).Error
+}
+
+// TouchUserActivity refreshes the last active time and keeps the client language code current
+func (db *DB) TouchUserActivity(userID int64, languageCode string) error {
+	updates := map[string]interface{}{
+		"last_active_at": time.Now(),
+	}
+	if languageCode != "" {
+		updates["language_code"] = languageCode
+	}
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Updates(updates).Error
+}
+
+// SetUserAfterHoursUntil records that the out-of-office reply was sent for the closed period ending at until
+func (db *DB) SetUserAfterHoursUntil(userID int64, until time.Time) error {
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Update("after_hours_until", until).Error
+}
+
+// SetUserTrusted marks a user as approved by an admin, or revokes it
+func (db *DB) SetUserTrusted(userID int64, trusted bool) error {
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Update("trusted", trusted).Error
+}
+
+// SetUserMenuPath records the self-service menu path the user last chose
+func (db *DB) SetUserMenuPath(userID int64, path string) error {
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Update("last_menu_path", path).Error
+}
+
+// SetUserLocale stores the locale a user picked; empty means auto-detect
+func (db *DB) SetUserLocale(userID int64, locale string) error {
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Update("locale", locale).Error
+}
+
+// SetUserCardMessageID records the pinned card message in the user's topic
+func (db *DB) SetUserCardMessageID(userID int64, messageID int) error {
+	return db.DB.Model(&models.User{}).Where("user_id = ?", userID).Update("card_message_id", messageID).Error
 }
\ No newline at end of file
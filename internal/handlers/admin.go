@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"html"
 	"log"
 	"strconv"
 	"strings"
+	"telegram-communication-bot/internal/database"
 	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
 	"time"
 
 	tgbot "github.com/go-telegram/bot"
@@ -16,22 +20,21 @@ import (
 func (h *Handlers) handleClearCommand(ctx context.Context, message *models.Message, args string) {
 	chatID := message.Chat.ID
 
-	if args == "" {
-		h.sendMessage(ctx, chatID, "❌ 请提供用户ID\n用法: /clear <user_id>")
+	user, _ := h.targetUserOrReply(ctx, message, args, h.catalog.Admin("clear.usage"), false)
+	if user == nil {
 		return
 	}
+	userID := user.UserID
 
-	userID, err := strconv.ParseInt(args, 10, 64)
-	if err != nil {
-		h.sendMessage(ctx, chatID, "❌ 无效的用户ID")
-		return
-	}
+	action := h.closeConversation(ctx, user)
 
-	user, err := h.db.GetUser(userID)
-	if err != nil {
-		h.sendMessage(ctx, chatID, "❌ 用户不存在")
-		return
-	}
+	h.sendMessage(ctx, chatID, h.catalog.Admin("clear.done", userID, user.FirstName, action))
+}
+
+// closeConversation closes (or, with DeleteTopicAsForeverBan, deletes and bans)
+// the user's topic and returns a description of the action taken.
+func (h *Handlers) closeConversation(ctx context.Context, user *dbmodels.User) string {
+	userID := user.UserID
 
 	if h.config.DeleteTopicAsForeverBan && user.MessageThreadID != 0 {
 		if err := h.forumService.DeleteForumTopic(ctx, user.MessageThreadID); err != nil {
@@ -53,50 +56,131 @@ func (h *Handlers) handleClearCommand(ctx context.Context, message *models.Messa
 		}
 	}
 
+	if err := h.sla.ResetConversation(userID); err != nil {
+		log.Printf("Error resetting SLA for user %d: %v", userID, err)
+	}
+
+	if h.csat.IsEnabled() && !h.config.DeleteTopicAsForeverBan {
+		h.sendCSATSurvey(ctx, user)
+	}
+
 	if h.config.DeleteUserMessageOnClearCmd {
 		log.Printf("Would delete messages for user %d", userID)
 	}
 
-	action := "已关闭"
+	action := h.catalog.Admin("clear.closed")
 	if h.config.DeleteTopicAsForeverBan {
-		action = "已删除并永久禁止"
+		action = h.catalog.Admin("clear.deleted_banned")
 	}
 
-	h.sendMessage(ctx, chatID, fmt.Sprintf("✅ 用户 %d (%s) 的对话%s", userID, user.FirstName, action))
+	return action
+}
+
+// handleBroadcastCommand broadcasts the replied-to message, or assembles a
+// multi-language broadcast: "/broadcast lang <locale>" adds the replied-to
+// message as that locale's variant and "/broadcast send" sends the draft.
+func (h *Handlers) handleBroadcastCommand(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+	adminID := message.From.ID
+
+	subcommand, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	switch strings.ToLower(subcommand) {
+	case "lang":
+		h.handleBroadcastVariant(ctx, message, strings.TrimSpace(rest))
+
+	case "send":
+		draft, ok := h.broadcasts.Take(adminID)
+		if !ok {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.no_draft"))
+			return
+		}
+		if _, ok := draft.Variants[h.catalog.DefaultLocale()]; !ok {
+			h.broadcasts.Restore(adminID, draft)
+			h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.default_required", h.catalog.DefaultLocale()))
+			return
+		}
+		if !h.startBroadcast(ctx, chatID, draft, rest) {
+			h.broadcasts.Restore(adminID, draft)
+		}
+
+	case "cancel":
+		if h.broadcasts.Discard(adminID) {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.cancelled"))
+		} else {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.no_draft"))
+		}
+
+	default:
+		if message.ReplyToMessage == nil || message.ReplyToMessage.ForumTopicCreated != nil {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.reply_required"))
+			return
+		}
+		draft := &services.BroadcastDraft{
+			Variants: map[string]*models.Message{h.catalog.DefaultLocale(): message.ReplyToMessage},
+		}
+		h.startBroadcast(ctx, chatID, draft, args)
+	}
 }
 
-func (h *Handlers) handleBroadcastCommand(ctx context.Context, message *models.Message) {
+// handleBroadcastVariant adds the replied-to message to the admin's draft
+func (h *Handlers) handleBroadcastVariant(ctx context.Context, message *models.Message, locale string) {
 	chatID := message.Chat.ID
 
-	if message.ReplyToMessage == nil {
-		h.sendMessage(ctx, chatID, "❌ 请回复一条消息以进行广播")
+	locale = strings.ToLower(locale)
+	if locale == "" || message.ReplyToMessage == nil || message.ReplyToMessage.ForumTopicCreated != nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.variant_usage"))
+		return
+	}
+	if !h.catalog.Has(locale) {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("common.unknown_locale", locale, strings.Join(h.catalog.Locales(), ", ")))
 		return
 	}
 
-	users, err := h.db.GetAllUsers()
+	locales := h.broadcasts.AddVariant(message.From.ID, locale, message.ReplyToMessage)
+	h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.variant_added", locale, strings.Join(locales, ", ")))
+}
+
+// startBroadcast sends the draft to all users, or those matching the tag
+// filters in args, in the background. Returns false if nothing was started.
+func (h *Handlers) startBroadcast(ctx context.Context, chatID int64, draft *services.BroadcastDraft, args string) bool {
+	var users []dbmodels.User
+	var err error
+	if tags := parseTagFilters(args); len(tags) > 0 {
+		users, err = h.db.GetUsersByTags(tags)
+	} else {
+		users, err = h.db.GetAllUsers()
+	}
 	if err != nil {
-		h.sendMessage(ctx, chatID, "❌ 获取用户列表失败")
+		h.sendMessage(ctx, chatID, h.catalog.Admin("common.user_list_failed"))
 		log.Printf("Error getting users for broadcast: %v", err)
-		return
+		return false
 	}
 
 	if len(users) == 0 {
-		h.sendMessage(ctx, chatID, "❌ 没有用户可以广播")
-		return
+		h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.no_users"))
+		return false
 	}
 
-	h.sendMessage(ctx, chatID, fmt.Sprintf("📡 开始广播消息给 %d 个用户...", len(users)))
+	h.sendMessage(ctx, chatID, h.catalog.Admin("broadcast.started", len(users)))
 
-	go h.performBroadcast(context.Background(), message.ReplyToMessage, users, chatID)
+	go h.performBroadcast(context.Background(), draft, users, chatID)
+	return true
 }
 
-func (h *Handlers) performBroadcast(ctx context.Context, broadcastMsg *models.Message, users []dbmodels.User, adminChatID int64) {
+// performBroadcast sends each user the variant for their locale, falling
+// back to the default locale's message, and reports counts per variant.
+func (h *Handlers) performBroadcast(ctx context.Context, draft *services.BroadcastDraft, users []dbmodels.User, adminChatID int64) {
 	successCount := 0
 	failCount := 0
+	delivered := make(map[string]int)
+	failed := make(map[string]int)
 
 	for _, user := range users {
+		broadcastMsg, locale := draft.Pick(h.userLocale(&user), h.catalog.DefaultLocale())
+
 		if h.db.IsUserBanned(user.UserID) {
 			failCount++
+			failed[locale]++
 			continue
 		}
 
@@ -104,14 +188,21 @@ func (h *Handlers) performBroadcast(ctx context.Context, broadcastMsg *models.Me
 		if err != nil {
 			log.Printf("Error broadcasting to user %d: %v", user.UserID, err)
 			failCount++
+			failed[locale]++
 		} else {
 			successCount++
+			delivered[locale]++
 		}
 
 		time.Sleep(50 * time.Millisecond)
 	}
 
-	summary := fmt.Sprintf("📡 广播完成!\n✅ 成功: %d\n❌ 失败: %d", successCount, failCount)
+	summary := h.catalog.Admin("broadcast.done", successCount, failCount)
+	if len(draft.Variants) > 1 {
+		for _, locale := range draft.Locales() {
+			summary += h.catalog.Admin("broadcast.locale_summary", locale, delivered[locale], failed[locale])
+		}
+	}
 	h.sendMessage(ctx, adminChatID, summary)
 }
 
@@ -120,7 +211,7 @@ func (h *Handlers) handleStatsCommand(ctx context.Context, message *models.Messa
 
 	totalUsers, err := h.db.CountUsers()
 	if err != nil {
-		h.sendMessage(ctx, chatID, "❌ 获取统计信息失败")
+		h.sendMessage(ctx, chatID, h.catalog.Admin("stats.failed"))
 		log.Printf("Error counting users: %v", err)
 		return
 	}
@@ -142,26 +233,49 @@ func (h *Handlers) handleStatsCommand(ctx context.Context, message *models.Messa
 		log.Printf("Error getting active topics: %v", err)
 	}
 
-	statsText := fmt.Sprintf(`📊 <b>机器人统计</b>
+	weekAgo := time.Now().AddDate(0, 0, -7)
+	avgFirst, firstCount, err := h.db.AverageResponseSeconds(dbmodels.ResponseKindFirst, weekAgo)
+	if err != nil {
+		log.Printf("Error averaging first response time: %v", err)
+	}
+	avgNext, nextCount, err := h.db.AverageResponseSeconds(dbmodels.ResponseKindNext, weekAgo)
+	if err != nil {
+		log.Printf("Error averaging next response time: %v", err)
+	}
+
+	monthAgo := time.Now().AddDate(0, 0, -30)
+	csatAvg, csatCount, csatAgents, err := h.db.SummarizeCSAT(monthAgo)
+	if err != nil {
+		log.Printf("Error summarizing CSAT ratings: %v", err)
+	}
 
-👥 <b>用户统计:</b>
-• 总用户数: %d
-• 活跃用户: %d
-• 被禁用户: %d
-• Premium用户: %d
+	filterHits, err := h.db.CountFilterHitsByAction(weekAgo)
+	if err != nil {
+		log.Printf("Error counting filter hits: %v", err)
+	}
+	var filterTotal int64
+	for _, count := range filterHits {
+		filterTotal += count
+	}
 
-💬 <b>对话统计:</b>
-• 活跃对话: %d
+	pendingReviews, err := h.db.CountPendingHeldMessages()
+	if err != nil {
+		log.Printf("Error counting held messages: %v", err)
+	}
 
-🔧 <b>系统设置:</b>
-• 消息间隔: %d秒
-• 删除对话永久禁止: %s
-• 清除时删除消息: %s`,
+	statsText := h.catalog.Admin("stats.template",
 		totalUsers,
 		activeUsers,
 		bannedUsers,
 		premiumUsers,
 		len(activeTopics),
+		h.formatWait(avgFirst), firstCount,
+		h.formatWait(avgNext), nextCount,
+		csatAvg, csatCount, h.formatCSATAgents(csatAgents),
+		filterTotal,
+		filterHits[dbmodels.FilterActionDrop], filterHits[dbmodels.FilterActionHold],
+		filterHits[dbmodels.FilterActionWarn], filterHits[dbmodels.FilterActionBan],
+		pendingReviews,
 		h.config.MessageInterval,
 		h.getBoolString(h.config.DeleteTopicAsForeverBan),
 		h.getBoolString(h.config.DeleteUserMessageOnClearCmd))
@@ -173,11 +287,33 @@ func (h *Handlers) handleStatsCommand(ctx context.Context, message *models.Messa
 	})
 }
 
+// formatCSATAgents renders per-agent average ratings as extra stats lines.
+func (h *Handlers) formatCSATAgents(agents []database.CSATAgentSummary) string {
+	var lines strings.Builder
+	for _, summary := range agents {
+		name := strconv.FormatInt(summary.AgentID, 10)
+		if agent, err := h.db.GetAgent(summary.AgentID); err == nil {
+			name = services.AgentDisplayName(agent)
+		}
+		lines.WriteString(fmt.Sprintf("\n  · %s: %.1f (%d)", html.EscapeString(name), summary.Avg, summary.Count))
+	}
+	return lines.String()
+}
+
+// formatWait renders a duration in seconds as minutes or hours.
+func (h *Handlers) formatWait(seconds float64) string {
+	d := time.Duration(seconds) * time.Second
+	if d < time.Hour {
+		return h.catalog.Admin("duration.minutes", d.Minutes())
+	}
+	return h.catalog.Admin("duration.hours", d.Hours())
+}
+
 func (h *Handlers) getBoolString(value bool) string {
 	if value {
-		return "启用"
+		return h.catalog.Admin("common.enabled")
 	}
-	return "禁用"
+	return h.catalog.Admin("common.disabled")
 }
 
 func (h *Handlers) banUser(userID int64, reason string) error {
@@ -201,9 +337,9 @@ func (h *Handlers) unbanUser(userID int64) error {
 func (h *Handlers) getUserInfo(user *dbmodels.User) string {
 	var info strings.Builder
 
-	info.WriteString(fmt.Sprintf("👤 <b>用户信息</b>\n\n"))
-	info.WriteString(fmt.Sprintf("🆔 <b>ID:</b> <code>%d</code>\n", user.UserID))
-	info.WriteString(fmt.Sprintf("📝 <b>姓名:</b> %s", user.FirstName))
+	info.WriteString(h.catalog.Admin("userinfo.title"))
+	info.WriteString(h.catalog.Admin("userinfo.id", user.UserID))
+	info.WriteString(h.catalog.Admin("userinfo.name", user.FirstName))
 
 	if user.LastName != "" {
 		info.WriteString(" " + user.LastName)
@@ -211,24 +347,24 @@ func (h *Handlers) getUserInfo(user *dbmodels.User) string {
 	info.WriteString("\n")
 
 	if user.Username != "" {
-		info.WriteString(fmt.Sprintf("👤 <b>用户名:</b> @%s\n", user.Username))
+		info.WriteString(h.catalog.Admin("userinfo.username", user.Username))
 	}
 
 	if user.IsPremium {
-		info.WriteString("⭐ <b>Premium用户</b>\n")
+		info.WriteString(h.catalog.Admin("userinfo.premium"))
 	}
 
-	info.WriteString(fmt.Sprintf("📅 <b>创建时间:</b> %s\n", user.CreatedAt.Format("2006-01-02 15:04:05")))
-	info.WriteString(fmt.Sprintf("🔄 <b>更新时间:</b> %s\n", user.UpdatedAt.Format("2006-01-02 15:04:05")))
+	info.WriteString(h.catalog.Admin("userinfo.created", user.CreatedAt.Format("2006-01-02 15:04:05")))
+	info.WriteString(h.catalog.Admin("userinfo.updated", user.UpdatedAt.Format("2006-01-02 15:04:05")))
 
 	if user.MessageThreadID != 0 {
-		info.WriteString(fmt.Sprintf("💬 <b>对话ID:</b> %d\n", user.MessageThreadID))
+		info.WriteString(h.catalog.Admin("userinfo.thread", user.MessageThreadID))
 	}
 
 	if h.db.IsUserBanned(user.UserID) {
-		info.WriteString("🚫 <b>状态:</b> 已禁止\n")
+		info.WriteString(h.catalog.Admin("userinfo.banned"))
 	} else {
-		info.WriteString("✅ <b>状态:</b> 正常\n")
+		info.WriteString(h.catalog.Admin("userinfo.active"))
 	}
 
 	return info.String()
@@ -237,28 +373,82 @@ func (h *Handlers) getUserInfo(user *dbmodels.User) string {
 func (h *Handlers) handleResetCommand(ctx context.Context, message *models.Message, args string) {
 	chatID := message.Chat.ID
 
-	if args == "" {
-		h.sendMessage(ctx, chatID, "❌ 请提供用户ID\n用法: /reset <user_id>")
+	user, _ := h.targetUserOrReply(ctx, message, args, h.catalog.Admin("reset.usage"), false)
+	if user == nil {
 		return
 	}
+	userID := user.UserID
 
-	userID, err := strconv.ParseInt(args, 10, 64)
-	if err != nil {
-		h.sendMessage(ctx, chatID, "❌ 无效的用户ID")
+	if err := h.forumService.ResetUserThreadID(userID); err != nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("reset.failed", userID, err))
+		log.Printf("Error resetting thread ID for user %d: %v", userID, err)
 		return
 	}
 
-	user, err := h.db.GetUser(userID)
-	if err != nil {
-		h.sendMessage(ctx, chatID, "❌ 用户不存在")
+	h.sendMessage(ctx, chatID, h.catalog.Admin("reset.done", userID, user.FirstName))
+}
+
+func (h *Handlers) handleReconcileCommand(ctx context.Context, message *models.Message) {
+	chatID := message.Chat.ID
+	threadID := message.MessageThreadID
+
+	h.sendThreadMessage(ctx, chatID, threadID, h.catalog.Admin("reconcile.started"))
+
+	go func() {
+		ctx := context.Background()
+		report, err := h.forumService.Reconcile(ctx)
+		if err != nil {
+			log.Printf("Error reconciling topics: %v", err)
+			h.sendThreadMessage(ctx, chatID, threadID, h.catalog.Admin("reconcile.failed", html.EscapeString(err.Error())))
+			return
+		}
+
+		h.sendThreadMessage(ctx, chatID, threadID, report.Format(h.catalog))
+	}()
+}
+
+func (h *Handlers) handleExportCommand(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	user, rest := h.targetUserOrReply(ctx, message, args, h.catalog.Admin("export.usage"), false)
+	if user == nil {
 		return
 	}
+	userID := user.UserID
 
-	if err := h.forumService.ResetUserThreadID(userID); err != nil {
-		h.sendMessage(ctx, chatID, fmt.Sprintf("❌ 重置用户 %d 的对话ID失败: %v", userID, err))
-		log.Printf("Error resetting thread ID for user %d: %v", userID, err)
+	format := services.ExportFormatHTML
+	if rest != "" {
+		format = strings.ToLower(rest)
+	}
+	if format == "markdown" {
+		format = services.ExportFormatMarkdown
+	}
+	if format != services.ExportFormatHTML && format != services.ExportFormatMarkdown {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("export.bad_format"))
+		return
+	}
+
+	filename, data, err := h.transcripts.ExportTranscript(user, format)
+	if err != nil {
+		log.Printf("Error exporting transcript for user %d: %v", userID, err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("export.failed"))
 		return
 	}
 
-	h.sendMessage(ctx, chatID, fmt.Sprintf("✅ 已重置用户 %d (%s) 的对话ID\n用户下次发消息时将创建新的对话", userID, user.FirstName))
+	// Prefer the user's own topic; fall back to wherever the command was sent.
+	threadID := message.MessageThreadID
+	if chatID == h.config.AdminGroupID && user.MessageThreadID != 0 {
+		threadID = user.MessageThreadID
+	}
+
+	_, err = h.bot.SendDocument(ctx, &tgbot.SendDocumentParams{
+		ChatID:          chatID,
+		MessageThreadID: threadID,
+		Document:        &models.InputFileUpload{Filename: filename, Data: bytes.NewReader(data)},
+		Caption:         h.catalog.Admin("export.caption", userID, user.FirstName),
+	})
+	if err != nil {
+		log.Printf("Error sending transcript document: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("export.send_failed"))
+	}
 }
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// autoAssign assigns a newly created topic to the next online agent and pings
+// them in the topic.
+func (h *Handlers) autoAssign(ctx context.Context, user *dbmodels.User, threadID int) {
+	agent, err := h.assignments.AutoAssign(user.UserID)
+	if err != nil {
+		log.Printf("Error auto-assigning user %d: %v", user.UserID, err)
+		return
+	}
+	if agent == nil {
+		return
+	}
+
+	user.AssignedAgentID = agent.AgentID
+	h.sendThreadMessage(ctx, h.config.AdminGroupID, threadID, h.catalog.Admin("assign.auto", agentMention(agent)))
+}
+
+func (h *Handlers) handleAssignCommand(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	user := h.userFromTopicContext(message)
+	if user == nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("assign.usage"))
+		return
+	}
+
+	var agent *dbmodels.Agent
+	var err error
+	if query := strings.TrimSpace(args); query != "" {
+		agent, err = h.assignments.FindAgent(query)
+	} else {
+		agent, err = h.db.GetAgent(message.From.ID)
+	}
+	if err != nil || !h.config.IsAdminUser(agent.AgentID) {
+		h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("assign.agent_not_found"))
+		return
+	}
+
+	if err := h.assignments.Assign(user.UserID, agent.AgentID); err != nil {
+		log.Printf("Error assigning user %d to agent %d: %v", user.UserID, agent.AgentID, err)
+		h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("assign.failed"))
+		return
+	}
+
+	h.refreshUserCard(ctx, user.UserID)
+	h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("assign.done", agentMention(agent)))
+}
+
+func (h *Handlers) handleUnassignCommand(ctx context.Context, message *models.Message) {
+	chatID := message.Chat.ID
+
+	user := h.userFromTopicContext(message)
+	if user == nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("common.topic_only"))
+		return
+	}
+
+	if err := h.assignments.Unassign(user.UserID); err != nil {
+		log.Printf("Error unassigning user %d: %v", user.UserID, err)
+		h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("unassign.failed"))
+		return
+	}
+
+	h.refreshUserCard(ctx, user.UserID)
+	h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("unassign.done"))
+}
+
+func (h *Handlers) handleMineCommand(ctx context.Context, message *models.Message) {
+	chatID := message.Chat.ID
+
+	users, err := h.db.GetOpenAssignedUsers(message.From.ID)
+	if err != nil {
+		log.Printf("Error listing assigned users: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("mine.failed"))
+		return
+	}
+
+	if len(users) == 0 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("mine.empty"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(h.catalog.Admin("mine.header", len(users)))
+	for i := range users {
+		if i == userListLimit {
+			text.WriteString(h.catalog.Admin("mine.more", len(users)-userListLimit))
+			break
+		}
+		text.WriteString("\n" + h.formatUserSummary(&users[i]))
+	}
+
+	_, err = h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:             chatID,
+		MessageThreadID:    message.MessageThreadID,
+		Text:               text.String(),
+		ParseMode:          models.ParseModeHTML,
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: tgbot.True()},
+	})
+	if err != nil {
+		log.Printf("Error sending assigned conversations: %v", err)
+	}
+}
+
+func (h *Handlers) handleAvailabilityCommand(ctx context.Context, message *models.Message, online bool) {
+	chatID := message.Chat.ID
+
+	if err := h.assignments.RecordAgent(message.From); err != nil {
+		log.Printf("Error recording agent %d: %v", message.From.ID, err)
+	}
+	if err := h.assignments.SetOnline(message.From.ID, online); err != nil {
+		log.Printf("Error updating availability for agent %d: %v", message.From.ID, err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("presence.failed"))
+		return
+	}
+
+	if online {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("presence.online"))
+	} else {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("presence.offline"))
+	}
+}
+
+// agentMention renders an agent as an HTML mention so they get notified.
+func agentMention(agent *dbmodels.Agent) string {
+	return fmt.Sprintf("<a href=\"tg://user?id=%d\">%s</a>", agent.AgentID, html.EscapeString(services.AgentDisplayName(agent)))
+}
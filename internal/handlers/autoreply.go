@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+// regexRuleSpec matches "/regex/" optionally followed by "| reply"
+var regexRuleSpec = regexp.MustCompile(`(?s)^/(.+?)/\s*(?:\|\s*(.*))?$`)
+
+func (h *Handlers) handleRuleCommand(ctx context.Context, message *models.Message, args string) {
+	subcommand, rest, _ := strings.Cut(args, " ")
+	switch strings.ToLower(subcommand) {
+	case "add":
+		h.handleRuleAdd(ctx, message, strings.TrimSpace(rest))
+	case "list":
+		h.handleRuleList(ctx, message)
+	case "del", "delete":
+		h.handleRuleDelete(ctx, message, strings.TrimSpace(rest))
+	case "lang":
+		h.handleRuleVariant(ctx, message, strings.TrimSpace(rest))
+	default:
+		h.sendMessage(ctx, message.Chat.ID, "❌ "+h.catalog.Admin("rule.usage"))
+	}
+}
+
+func (h *Handlers) handleRuleAdd(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+	rule := &dbmodels.AutoReplyRule{CreatedBy: message.From.ID}
+
+	if rest, ok := strings.CutPrefix(args, "forward "); ok {
+		rule.Forward = true
+		args = strings.TrimSpace(rest)
+	}
+
+	var spec, reply string
+	if m := regexRuleSpec.FindStringSubmatch(args); m != nil {
+		spec, reply = "/"+m[1]+"/", m[2]
+	} else {
+		spec, reply, _ = strings.Cut(args, "|")
+	}
+	rule.Pattern, rule.IsRegex = services.ParsePattern(spec)
+	rule.ReplyText = strings.TrimSpace(reply)
+
+	if rule.ReplyText == "" && message.ReplyToMessage != nil && message.ReplyToMessage.ForumTopicCreated == nil {
+		rule.SourceChatID = chatID
+		rule.SourceMessageID = message.ReplyToMessage.ID
+	}
+
+	if rule.Pattern == "" || (rule.ReplyText == "" && rule.SourceMessageID == 0) {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.missing_parts")+h.catalog.Admin("rule.usage"))
+		return
+	}
+
+	if err := h.autoReplies.AddRule(rule); err != nil {
+		log.Printf("Error adding auto-reply rule: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.add_failed", err))
+		return
+	}
+
+	h.sendMessage(ctx, chatID, h.catalog.Admin("rule.added", rule.ID))
+}
+
+func (h *Handlers) handleRuleList(ctx context.Context, message *models.Message) {
+	chatID := message.Chat.ID
+
+	rules, err := h.autoReplies.ListRules()
+	if err != nil {
+		log.Printf("Error listing auto-reply rules: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.list_failed"))
+		return
+	}
+	if len(rules) == 0 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.list_empty"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(h.catalog.Admin("rule.list_header", len(rules)))
+	for _, rule := range rules {
+		pattern := rule.Pattern
+		if rule.IsRegex {
+			pattern = "/" + pattern + "/"
+		}
+		reply := truncateRunes(rule.ReplyText, 40)
+		if rule.SourceMessageID != 0 {
+			reply = h.catalog.Admin("rule.saved_message")
+		}
+		forward := h.catalog.Admin("rule.no_forward")
+		if rule.Forward {
+			forward = h.catalog.Admin("rule.forward")
+		}
+		text.WriteString(h.catalog.Admin("rule.list_item",
+			rule.ID, html.EscapeString(pattern), html.EscapeString(reply), forward, rule.HitCount))
+		if locales := h.autoReplies.VariantLocales(rule.ID); len(locales) > 0 {
+			text.WriteString(h.catalog.Admin("rule.list_variants", strings.Join(locales, ", ")))
+		}
+	}
+
+	_, err = h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:          chatID,
+		MessageThreadID: message.MessageThreadID,
+		Text:            text.String(),
+		ParseMode:       models.ParseModeHTML,
+	})
+	if err != nil {
+		log.Printf("Error sending rule list: %v", err)
+	}
+}
+
+// handleRuleVariant sets the reply of a rule for one locale, given as text
+// or by replying to the message to send
+func (h *Handlers) handleRuleVariant(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	fields := strings.SplitN(args, " ", 3)
+	if len(fields) < 2 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.variant_usage"))
+		return
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(fields[0], "#"), 10, 64)
+	if err != nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.variant_usage"))
+		return
+	}
+	locale := strings.ToLower(fields[1])
+	if !h.catalog.Has(locale) {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("common.unknown_locale", locale, strings.Join(h.catalog.Locales(), ", ")))
+		return
+	}
+
+	variant := &dbmodels.AutoReplyVariant{RuleID: uint(id), Locale: locale}
+	if len(fields) == 3 {
+		variant.ReplyText = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(fields[2]), "|"))
+	}
+	if variant.ReplyText == "" && message.ReplyToMessage != nil && message.ReplyToMessage.ForumTopicCreated == nil {
+		variant.SourceChatID = chatID
+		variant.SourceMessageID = message.ReplyToMessage.ID
+	}
+	if variant.ReplyText == "" && variant.SourceMessageID == 0 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.variant_usage"))
+		return
+	}
+
+	if err := h.autoReplies.SetVariant(variant); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("rule.not_found", id))
+			return
+		}
+		log.Printf("Error saving %s variant of auto-reply rule %d: %v", locale, id, err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.variant_failed"))
+		return
+	}
+
+	h.sendMessage(ctx, chatID, h.catalog.Admin("rule.variant_saved", id, locale))
+}
+
+func (h *Handlers) handleRuleDelete(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(args, "#"), 10, 64)
+	if err != nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.delete_usage"))
+		return
+	}
+
+	if err := h.autoReplies.DeleteRule(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("rule.not_found", id))
+			return
+		}
+		log.Printf("Error deleting auto-reply rule %d: %v", id, err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("rule.delete_failed"))
+		return
+	}
+
+	h.sendMessage(ctx, chatID, h.catalog.Admin("rule.deleted", id))
+}
+
+// sendAutoReply answers the user with the rule's stored message or text
+func (h *Handlers) sendAutoReply(ctx context.Context, chatID int64, rule *dbmodels.AutoReplyRule) {
+	if rule.SourceMessageID != 0 {
+		_, err := h.bot.CopyMessage(ctx, &tgbot.CopyMessageParams{
+			ChatID:     chatID,
+			FromChatID: rule.SourceChatID,
+			MessageID:  rule.SourceMessageID,
+		})
+		if err != nil {
+			log.Printf("Error copying auto-reply message for rule %d: %v", rule.ID, err)
+		}
+		return
+	}
+	h.sendMessage(ctx, chatID, rule.ReplyText)
+}
+
+// noteAutoReply tells agents in the user's topic which rule already answered
+func (h *Handlers) noteAutoReply(ctx context.Context, userID int64, rule *dbmodels.AutoReplyRule) {
+	user, err := h.db.GetUser(userID)
+	if err != nil || user.MessageThreadID == 0 {
+		return
+	}
+	h.sendThreadMessage(ctx, h.config.AdminGroupID, user.MessageThreadID,
+		h.catalog.Admin("rule.topic_note", rule.ID))
+}
+
+// truncateRunes shortens s to at most n runes, adding an ellipsis if cut
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"time"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// sendUserCard posts the contact card into a newly created topic and pins it.
+func (h *Handlers) sendUserCard(ctx context.Context, user *dbmodels.User, threadID int) {
+	cardMsg, err := h.messageService.SendContactCard(ctx, h.bot, user, h.config.AdminGroupID, threadID)
+	if err != nil {
+		log.Printf("Error sending user card: %v", err)
+		return
+	}
+
+	if err := h.messageService.CreateMessageMap(0, cardMsg.ID, user.UserID); err != nil {
+		log.Printf("Error creating user card message mapping: %v", err)
+	}
+
+	if err := h.messageService.PinMessage(ctx, h.bot, h.config.AdminGroupID, cardMsg.ID); err != nil {
+		log.Printf("Error pinning user card: %v", err)
+	}
+
+	if err := h.db.SetUserCardMessageID(user.UserID, cardMsg.ID); err != nil {
+		log.Printf("Error saving user card message ID: %v", err)
+	}
+	user.CardMessageID = cardMsg.ID
+}
+
+// refreshUserCard re-renders the pinned card after the user's data changed.
+func (h *Handlers) refreshUserCard(ctx context.Context, userID int64) {
+	if !h.config.HasAdminGroup() {
+		return
+	}
+
+	user, err := h.db.GetUser(userID)
+	if err != nil {
+		return
+	}
+
+	if err := h.messageService.UpdateContactCard(ctx, h.bot, user, h.config.AdminGroupID); err != nil {
+		log.Printf("Error updating user card for %d: %v", userID, err)
+	}
+
+	h.cardMu.Lock()
+	h.cardRefreshedAt[userID] = time.Now()
+	h.cardMu.Unlock()
+}
+
+// cardRefreshInterval limits how often incoming messages alone re-render a
+// card; its message count and last-active time may lag by this much
+const cardRefreshInterval = 5 * time.Minute
+
+// touchUserCard refreshes the card for a new user message, at most once per
+// cardRefreshInterval per user.
+func (h *Handlers) touchUserCard(ctx context.Context, userID int64) {
+	now := time.Now()
+
+	h.cardMu.Lock()
+	last, ok := h.cardRefreshedAt[userID]
+	if ok && now.Sub(last) < cardRefreshInterval {
+		h.cardMu.Unlock()
+		return
+	}
+	for id, at := range h.cardRefreshedAt {
+		if now.Sub(at) >= cardRefreshInterval {
+			delete(h.cardRefreshedAt, id)
+		}
+	}
+	h.cardRefreshedAt[userID] = now
+	h.cardMu.Unlock()
+
+	h.refreshUserCard(ctx, userID)
+}
+
+// cardActionLabels maps the card actions that need a second tap to confirm
+// to the catalog key of their label.
+var cardActionLabels = map[string]string{
+	"ban":   "card.action_ban",
+	"close": "card.action_close",
+	"reset": "card.action_reset",
+}
+
+// handleCardCallback handles the action buttons on a user card.
+// Callback data has the form card_<action>_<user_id>[_confirm]; destructive
+// actions first swap the keyboard for a confirmation prompt and only run once
+// the _confirm variant is pressed.
+func (h *Handlers) handleCardCallback(ctx context.Context, cq *models.CallbackQuery) {
+	if !h.config.IsAdminUser(cq.From.ID) {
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.no_permission"), true)
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(cq.Data, "card_"), "_", 3)
+	if len(parts) < 2 {
+		h.answerCallback(ctx, cq.ID, "", false)
+		return
+	}
+
+	action := parts[0]
+	confirmed := len(parts) == 3 && parts[2] == "confirm"
+	userID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.invalid_user_id"), true)
+		return
+	}
+
+	user, err := h.db.GetUser(userID)
+	if err != nil {
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.user_not_found"), true)
+		return
+	}
+
+	if labelKey, destructive := cardActionLabels[action]; destructive && !confirmed {
+		label := h.catalog.Admin(labelKey)
+		h.setCardKeyboard(ctx, cq, models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{Text: h.catalog.Admin("card.confirm_button", label), CallbackData: fmt.Sprintf("card_%s_%d_confirm", action, userID)},
+					{Text: h.catalog.Admin("card.cancel_button"), CallbackData: fmt.Sprintf("card_cancel_%d", userID)},
+				},
+			},
+		})
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.confirm_hint", label), false)
+		return
+	}
+
+	switch action {
+	case "ban":
+		if err := h.banUser(userID, "Banned by admin from user card"); err != nil {
+			log.Printf("Error banning user %d: %v", userID, err)
+			h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.ban_failed"), true)
+			return
+		}
+		h.refreshCardFromCallback(ctx, cq, user)
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.banned", userID), true)
+
+	case "unban":
+		if err := h.unbanUser(userID); err != nil {
+			log.Printf("Error unbanning user %d: %v", userID, err)
+			h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.unban_failed"), true)
+			return
+		}
+		h.refreshCardFromCallback(ctx, cq, user)
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.unbanned", userID), true)
+
+	case "close":
+		result := h.closeConversation(ctx, user)
+		h.restoreCardKeyboard(ctx, cq, userID)
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.closed", userID, result), true)
+
+	case "reset":
+		if err := h.forumService.ResetUserThreadID(userID); err != nil {
+			log.Printf("Error resetting thread ID for user %d: %v", userID, err)
+			h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.reset_failed"), true)
+			return
+		}
+		h.restoreCardKeyboard(ctx, cq, userID)
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.reset_done", userID), true)
+
+	case "history":
+		h.answerCallback(ctx, cq.ID, "", false)
+		if msg := cq.Message.Message; msg != nil {
+			h.sendThreadMessage(ctx, msg.Chat.ID, msg.MessageThreadID, h.getUserHistory(user))
+		}
+
+	case "cancel":
+		h.restoreCardKeyboard(ctx, cq, userID)
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.cancelled"), false)
+
+	default:
+		h.answerCallback(ctx, cq.ID, "", false)
+		log.Printf("Unknown card action: %s", action)
+	}
+}
+
+// getUserHistory summarizes what the bot knows about a user's conversation.
+func (h *Handlers) getUserHistory(user *dbmodels.User) string {
+	var info strings.Builder
+
+	info.WriteString(h.catalog.Admin("history.header", user.UserID))
+	info.WriteString(h.catalog.Admin("history.first_contact", user.CreatedAt.Format("2006-01-02 15:04:05")))
+	if !user.LastActiveAt.IsZero() {
+		info.WriteString(h.catalog.Admin("history.last_active", user.LastActiveAt.Format("2006-01-02 15:04:05")))
+	}
+	info.WriteString(h.catalog.Admin("history.messages", user.MessageCount))
+
+	if relayed, err := h.db.CountMessageMaps(user.UserID); err == nil {
+		info.WriteString(h.catalog.Admin("history.relayed", relayed))
+	}
+
+	if user.MessageThreadID != 0 {
+		status, _ := h.forumService.GetForumTopicStatus(user.MessageThreadID)
+		info.WriteString(h.catalog.Admin("history.topic", user.MessageThreadID, status))
+	} else {
+		info.WriteString(h.catalog.Admin("history.no_topic"))
+	}
+
+	if banStatus, err := h.db.GetBanStatus(user.UserID); err == nil {
+		if banStatus.IsBanned {
+			info.WriteString(h.catalog.Admin("history.banned_at", banStatus.BannedAt.Format("2006-01-02 15:04:05")))
+			if banStatus.Reason != "" {
+				info.WriteString(h.catalog.Admin("history.ban_reason", html.EscapeString(banStatus.Reason)))
+			}
+		} else {
+			info.WriteString(h.catalog.Admin("history.unbanned_at", banStatus.UpdatedAt.Format("2006-01-02 15:04:05")))
+		}
+	}
+
+	return info.String()
+}
+
+// setCardKeyboard replaces the keyboard of the card the callback came from.
+func (h *Handlers) setCardKeyboard(ctx context.Context, cq *models.CallbackQuery, keyboard models.InlineKeyboardMarkup) {
+	msg := cq.Message.Message
+	if msg == nil {
+		return
+	}
+
+	_, err := h.bot.EditMessageReplyMarkup(ctx, &tgbot.EditMessageReplyMarkupParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		log.Printf("Error editing card keyboard: %v", err)
+	}
+}
+
+// refreshCardFromCallback re-renders the card after an action that changed
+// it. The refresh restores the pinned card's keyboard in the same edit, so
+// only an older copy of the card needs its keyboard restored separately.
+func (h *Handlers) refreshCardFromCallback(ctx context.Context, cq *models.CallbackQuery, user *dbmodels.User) {
+	h.refreshUserCard(ctx, user.UserID)
+	if msg := cq.Message.Message; msg != nil && msg.ID != user.CardMessageID {
+		h.restoreCardKeyboard(ctx, cq, user.UserID)
+	}
+}
+
+// restoreCardKeyboard puts the regular action buttons back on the card.
+func (h *Handlers) restoreCardKeyboard(ctx context.Context, cq *models.CallbackQuery, userID int64) {
+	h.setCardKeyboard(ctx, cq, h.messageService.ContactCardKeyboard(userID, h.db.IsUserBanned(userID)))
+}
+
+func (h *Handlers) sendThreadMessage(ctx context.Context, chatID int64, threadID int, text string) {
+	_, err := h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:          chatID,
+		MessageThreadID: threadID,
+		Text:            text,
+		ParseMode:       models.ParseModeHTML,
+	})
+	if err != nil {
+		log.Printf("Error sending thread message: %v", err)
+	}
+}
+
+func (h *Handlers) answerCallback(ctx context.Context, callbackQueryID string, text string, showAlert bool) {
+	_, err := h.bot.AnswerCallbackQuery(ctx, &tgbot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQueryID,
+		Text:            text,
+		ShowAlert:       showAlert,
+	})
+	if err != nil {
+		log.Printf("Error answering callback query: %v", err)
+	}
+}
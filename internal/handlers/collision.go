@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// softLockHolder returns the name of the agent holding the conversation's soft
+// lock against agentID. The assigned agent holds it from the moment of
+// assignment; unassigned conversations are locked by whoever replied within
+// the collision window.
+func (h *Handlers) softLockHolder(threadID int, user *dbmodels.User, agentID int64) (string, bool) {
+	if user.AssignedAgentID == 0 {
+		return h.collisions.Check(threadID, agentID)
+	}
+	if user.AssignedAgentID == agentID {
+		return "", false
+	}
+
+	name := strconv.FormatInt(user.AssignedAgentID, 10)
+	if agent, err := h.db.GetAgent(user.AssignedAgentID); err == nil {
+		name = services.AgentDisplayName(agent)
+	}
+	return name, true
+}
+
+// holdAdminReply keeps a reply to a topic another agent is working on and
+// asks its author to confirm before it is relayed.
+func (h *Handlers) holdAdminReply(ctx context.Context, message *models.Message, user *dbmodels.User, otherAgent string) {
+	h.collisions.Hold(message, user.UserID)
+
+	_, err := h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:          message.Chat.ID,
+		MessageThreadID: message.MessageThreadID,
+		Text:            h.catalog.Admin("collision.hold", html.EscapeString(otherAgent)),
+		ParseMode:       models.ParseModeHTML,
+		ReplyParameters: &models.ReplyParameters{MessageID: message.ID},
+		ReplyMarkup: models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{Text: h.catalog.Admin("collision.send_button"), CallbackData: fmt.Sprintf("collision_send_%d", message.ID)},
+					{Text: h.catalog.Admin("collision.cancel_button"), CallbackData: fmt.Sprintf("collision_cancel_%d", message.ID)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("Error sending soft lock prompt: %v", err)
+	}
+}
+
+// handleCollisionCallback handles the soft-lock confirmation buttons.
+// Callback data has the form collision_<send|cancel>_<group_message_id>.
+func (h *Handlers) handleCollisionCallback(ctx context.Context, cq *models.CallbackQuery) {
+	parts := strings.SplitN(strings.TrimPrefix(cq.Data, "collision_"), "_", 2)
+	if len(parts) != 2 {
+		h.answerCallback(ctx, cq.ID, "", false)
+		return
+	}
+
+	messageID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		h.answerCallback(ctx, cq.ID, "", false)
+		return
+	}
+
+	held, ok := h.collisions.Release(messageID)
+	if !ok {
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("collision.expired"), true)
+		h.deleteCallbackMessage(ctx, cq)
+		return
+	}
+
+	if held.AgentID != cq.From.ID {
+		h.collisions.Hold(held.Message, held.UserID)
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("collision.not_author"), true)
+		return
+	}
+
+	h.deleteCallbackMessage(ctx, cq)
+
+	if parts[0] != "send" {
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("collision.cancelled"), false)
+		return
+	}
+
+	user, err := h.db.GetUser(held.UserID)
+	if err != nil {
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.user_not_found"), true)
+		return
+	}
+
+	h.relayAdminReply(ctx, held.Message, user)
+	h.answerCallback(ctx, cq.ID, h.catalog.Admin("collision.sent"), false)
+}
+
+func (h *Handlers) deleteCallbackMessage(ctx context.Context, cq *models.CallbackQuery) {
+	msg := cq.Message.Message
+	if msg == nil {
+		return
+	}
+	h.bot.DeleteMessage(ctx, &tgbot.DeleteMessageParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+	})
+}
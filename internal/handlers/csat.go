@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// sendCSATSurvey asks the user to rate the conversation that was just closed
+func (h *Handlers) sendCSATSurvey(ctx context.Context, user *dbmodels.User) {
+	survey, err := h.csat.StartSurvey(user)
+	if err != nil {
+		log.Printf("Error starting CSAT survey for user %d: %v", user.UserID, err)
+		return
+	}
+
+	sent, err := h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:      user.UserID,
+		Text:        h.catalog.T(h.userLocale(user), "csat.prompt"),
+		ReplyMarkup: h.csat.Keyboard(survey.ID),
+	})
+	if err != nil {
+		log.Printf("Error sending CSAT survey to user %d: %v", user.UserID, err)
+		return
+	}
+
+	if err := h.csat.AttachPrompt(survey, sent.ID); err != nil {
+		log.Printf("Error saving CSAT prompt for user %d: %v", user.UserID, err)
+	}
+}
+
+func (h *Handlers) handleCSATCallback(ctx context.Context, cq *models.CallbackQuery) {
+	parts := strings.Split(strings.TrimPrefix(cq.Data, services.CSATCallbackPrefix), "_")
+	if len(parts) != 2 {
+		h.answerCallback(ctx, cq.ID, "", false)
+		return
+	}
+	surveyID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		h.answerCallback(ctx, cq.ID, "", false)
+		return
+	}
+	score, err := strconv.Atoi(parts[1])
+	if err != nil {
+		h.answerCallback(ctx, cq.ID, "", false)
+		return
+	}
+
+	locale := h.senderLocale(&cq.From)
+	survey, err := h.csat.Rate(uint(surveyID), cq.From.ID, score)
+	switch {
+	case errors.Is(err, services.ErrSurveyAlreadyRated):
+		h.answerCallback(ctx, cq.ID, h.catalog.T(locale, "csat.already_rated"), false)
+		return
+	case err != nil:
+		log.Printf("Error rating CSAT survey %d: %v", surveyID, err)
+		h.answerCallback(ctx, cq.ID, h.catalog.T(locale, "csat.failed"), true)
+		return
+	}
+	h.answerCallback(ctx, cq.ID, h.catalog.T(locale, "csat.thanks"), false)
+
+	if msg := cq.Message.Message; msg != nil {
+		_, err := h.bot.EditMessageText(ctx, &tgbot.EditMessageTextParams{
+			ChatID:    msg.Chat.ID,
+			MessageID: msg.ID,
+			Text:      h.catalog.T(locale, "csat.thanks_with_score", services.FormatStars(score)),
+		})
+		if err != nil {
+			log.Printf("Error updating CSAT prompt: %v", err)
+		}
+	}
+
+	commentPrompt, err := h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID: cq.From.ID,
+		Text:   h.catalog.T(locale, "csat.comment_prompt"),
+		ReplyMarkup: &models.ForceReply{
+			ForceReply:            true,
+			InputFieldPlaceholder: h.catalog.T(locale, "csat.comment_placeholder"),
+		},
+	})
+	if err != nil {
+		log.Printf("Error sending CSAT comment prompt: %v", err)
+	} else if err := h.csat.AttachCommentPrompt(survey, commentPrompt.ID); err != nil {
+		log.Printf("Error saving CSAT comment prompt: %v", err)
+	}
+
+	h.postCSATToTopic(ctx, survey, h.catalog.Admin("csat.topic_rating", services.FormatStars(score)))
+}
+
+// handleCSATComment stores a reply to the comment prompt. Returns true if the
+// message was a survey comment and must not be forwarded.
+func (h *Handlers) handleCSATComment(ctx context.Context, message *models.Message) bool {
+	if message.ReplyToMessage == nil || message.Text == "" {
+		return false
+	}
+
+	survey, err := h.csat.AddComment(message.From.ID, message.ReplyToMessage.ID, message.Text)
+	if errors.Is(err, services.ErrSurveyNotFound) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Error saving CSAT comment for user %d: %v", message.From.ID, err)
+		return false
+	}
+
+	h.sendMessage(ctx, message.Chat.ID, h.catalog.T(h.senderLocale(message.From), "csat.comment_received"))
+	h.postCSATToTopic(ctx, survey, h.catalog.Admin("csat.topic_comment", html.EscapeString(message.Text)))
+	return true
+}
+
+// postCSATToTopic shares the rating in the conversation's topic, naming the credited agent
+func (h *Handlers) postCSATToTopic(ctx context.Context, survey *dbmodels.CSATSurvey, text string) {
+	if !h.config.HasAdminGroup() || survey.MessageThreadID == 0 {
+		return
+	}
+	if survey.AgentID != 0 {
+		if agent, err := h.db.GetAgent(survey.AgentID); err == nil {
+			text += h.catalog.Admin("csat.topic_agent", agentMention(agent))
+		}
+	}
+	h.sendThreadMessage(ctx, h.config.AdminGroupID, survey.MessageThreadID, text)
+}
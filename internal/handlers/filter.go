@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+func (h *Handlers) handleFilterCommand(ctx context.Context, message *models.Message, args string) {
+	subcommand, rest, _ := strings.Cut(args, " ")
+	switch strings.ToLower(subcommand) {
+	case "add":
+		h.handleFilterAdd(ctx, message, strings.TrimSpace(rest))
+	case "list":
+		h.handleFilterList(ctx, message)
+	case "del", "delete":
+		h.handleFilterDelete(ctx, message, strings.TrimSpace(rest))
+	default:
+		h.sendMessage(ctx, message.Chat.ID, "❌ "+h.catalog.Admin("filter.usage"))
+	}
+}
+
+func (h *Handlers) handleFilterAdd(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	action, spec, _ := strings.Cut(args, " ")
+	rule := &dbmodels.FilterRule{Action: strings.ToLower(action), CreatedBy: message.From.ID}
+	rule.Kind, rule.Pattern = services.ParseFilterSpec(spec)
+
+	if strings.TrimSpace(spec) == "" {
+		h.sendMessage(ctx, chatID, "❌ "+h.catalog.Admin("filter.usage"))
+		return
+	}
+
+	if err := h.contentFilter.AddRule(rule); err != nil {
+		log.Printf("Error adding filter rule: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("filter.add_failed", err))
+		return
+	}
+
+	h.sendMessage(ctx, chatID, h.catalog.Admin("filter.added", rule.ID))
+}
+
+func (h *Handlers) handleFilterList(ctx context.Context, message *models.Message) {
+	chatID := message.Chat.ID
+
+	rules, err := h.contentFilter.ListRules()
+	if err != nil {
+		log.Printf("Error listing filter rules: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("filter.list_failed"))
+		return
+	}
+	if len(rules) == 0 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("filter.list_empty"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(h.catalog.Admin("filter.list_header", len(rules)))
+	for _, rule := range rules {
+		pattern := rule.Pattern
+		switch rule.Kind {
+		case dbmodels.FilterKindRegex:
+			pattern = "/" + pattern + "/"
+		case dbmodels.FilterKindDomain:
+			pattern = "domain:" + pattern
+		case dbmodels.FilterKindLink:
+			pattern = dbmodels.FilterKindLink
+		}
+		text.WriteString(h.catalog.Admin("filter.list_item",
+			rule.ID, html.EscapeString(pattern), h.catalog.Admin("filter.action_"+rule.Action), rule.HitCount))
+	}
+
+	_, err = h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:             chatID,
+		MessageThreadID:    message.MessageThreadID,
+		Text:               text.String(),
+		ParseMode:          models.ParseModeHTML,
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: tgbot.True()},
+	})
+	if err != nil {
+		log.Printf("Error sending filter list: %v", err)
+	}
+}
+
+func (h *Handlers) handleFilterDelete(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(args, "#"), 10, 64)
+	if err != nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("filter.delete_usage"))
+		return
+	}
+
+	if err := h.contentFilter.DeleteRule(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("filter.not_found", id))
+			return
+		}
+		log.Printf("Error deleting filter rule %d: %v", id, err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("filter.delete_failed"))
+		return
+	}
+
+	h.sendMessage(ctx, chatID, h.catalog.Admin("filter.deleted", id))
+}
+
+// handleFilterHit applies the action of the rule a user message tripped.
+// The message is not forwarded to the user's topic, unless it was to be held
+// and could not be queued for review.
+func (h *Handlers) handleFilterHit(ctx context.Context, message *models.Message, user *dbmodels.User, rule *dbmodels.FilterRule) {
+	chatID := message.Chat.ID
+	locale := h.userLocale(user)
+
+	switch rule.Action {
+	case dbmodels.FilterActionHold:
+		if h.holdForReview(ctx, message, user, dbmodels.HeldKindFilter, h.catalog.Admin("review.reason_filter", rule.ID)) {
+			h.sendMessage(ctx, chatID, h.catalog.T(locale, "filter.held"))
+		}
+
+	case dbmodels.FilterActionWarn:
+		h.sendMessage(ctx, chatID, h.catalog.T(locale, "filter.warning"))
+
+	case dbmodels.FilterActionBan:
+		if err := h.banUser(user.UserID, fmt.Sprintf("Auto-banned by filter rule #%d", rule.ID)); err != nil {
+			log.Printf("Error banning user %d: %v", user.UserID, err)
+			return
+		}
+		h.refreshUserCard(ctx, user.UserID)
+		h.noteFilterHit(ctx, message, user, rule)
+	}
+}
+
+// noteFilterHit tells admins what a rule stopped, in the user's topic if
+// they have one and in the general topic otherwise
+func (h *Handlers) noteFilterHit(ctx context.Context, message *models.Message, user *dbmodels.User, rule *dbmodels.FilterRule) {
+	if !h.config.HasAdminGroup() {
+		return
+	}
+
+	text := h.catalog.Admin("filter.topic_note",
+		rule.ID, h.catalog.Admin("filter.action_"+rule.Action),
+		html.EscapeString(user.FirstName), user.UserID, html.EscapeString(truncateRunes(messageText(message), 200)))
+	h.sendThreadMessage(ctx, h.config.AdminGroupID, user.MessageThreadID, text)
+}
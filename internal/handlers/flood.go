@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"html"
+	"log"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+	"time"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// checkFlood runs duplicate and flood detection on a user message. Returns
+// true when the message has been dealt with and must not be forwarded.
+func (h *Handlers) checkFlood(ctx context.Context, message *models.Message, user *dbmodels.User) bool {
+	if !h.floodGuard.IsEnabled() {
+		return false
+	}
+
+	verdict := h.floodGuard.Check(user.UserID, message)
+	if verdict.Flooding {
+		h.muteForFlood(ctx, message, user)
+		return true
+	}
+	return verdict.Duplicate && h.collapseDuplicate(ctx, user, verdict)
+}
+
+// muteForFlood mutes a user who sent more messages than the flood threshold
+// allows and lets both sides know
+func (h *Handlers) muteForFlood(ctx context.Context, message *models.Message, user *dbmodels.User) {
+	userID := user.UserID
+	minutes := h.config.FloodMuteMinutes
+
+	mute := &dbmodels.MuteStatus{
+		UserID:     userID,
+		MutedUntil: time.Now().Add(time.Duration(minutes) * time.Minute),
+		Reason:     "Flooding",
+		Notified:   true,
+	}
+	if err := h.db.MuteUser(mute); err != nil {
+		log.Printf("Error muting user %d: %v", userID, err)
+		return
+	}
+	h.floodGuard.Forget(userID)
+	log.Printf("User %d muted for %d minutes for flooding", userID, minutes)
+
+	h.sendMessage(ctx, message.Chat.ID, h.catalog.T(h.userLocale(user), "flood.muted", minutes))
+
+	if !h.config.HasAdminGroup() || user.MessageThreadID == 0 {
+		return
+	}
+	h.refreshUserCard(ctx, userID)
+	h.sendThreadMessage(ctx, h.config.AdminGroupID, user.MessageThreadID,
+		h.catalog.Admin("flood.topic_note", html.EscapeString(message.From.FirstName), userID, minutes))
+}
+
+// collapseDuplicate counts a repeated message on a single "×N" note attached
+// to the first forwarded copy instead of forwarding it again. Returns false
+// when the first copy can no longer be found, so the repeat is forwarded
+// normally.
+func (h *Handlers) collapseDuplicate(ctx context.Context, user *dbmodels.User, verdict services.FloodVerdict) bool {
+	if !h.config.HasAdminGroup() || user.MessageThreadID == 0 {
+		return false
+	}
+	userID := user.UserID
+
+	messageMap, err := h.messageService.GetGroupMessageFromUser(verdict.FirstMessageID, userID)
+	if err != nil {
+		return false
+	}
+
+	text := h.catalog.Admin("flood.duplicate", verdict.Count)
+
+	if verdict.NoteMessageID != 0 {
+		_, err := h.bot.EditMessageText(ctx, &tgbot.EditMessageTextParams{
+			ChatID:    h.config.AdminGroupID,
+			MessageID: verdict.NoteMessageID,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+		if err == nil || strings.Contains(err.Error(), "message is not modified") {
+			return true
+		}
+		log.Printf("Error updating duplicate note: %v", err)
+	}
+
+	sent, err := h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:              h.config.AdminGroupID,
+		MessageThreadID:     user.MessageThreadID,
+		Text:                text,
+		ParseMode:           models.ParseModeHTML,
+		DisableNotification: true,
+		ReplyParameters:     &models.ReplyParameters{MessageID: messageMap.GroupChatMessageID},
+	})
+	if err != nil {
+		log.Printf("Error sending duplicate note: %v", err)
+		return false
+	}
+	h.floodGuard.SetNote(userID, verdict.Key, sent.ID)
+	return true
+}
@@ -2,14 +2,17 @@ package handlers
 
 import (
 	"context"
-	"fmt"
+	"html"
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"telegram-communication-bot/internal/config"
 	"telegram-communication-bot/internal/database"
+	"telegram-communication-bot/internal/i18n"
 	dbmodels "telegram-communication-bot/internal/models"
 	"telegram-communication-bot/internal/services"
+	"time"
 
 	tgbot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -23,6 +26,23 @@ type Handlers struct {
 	forumService   *services.ForumService
 	rateLimiter    *services.RateLimiter
 	captchaService *services.CaptchaService
+	transcripts    *services.TranscriptService
+	assignments    *services.AssignmentService
+	collisions     *services.CollisionDetector
+	sla            *services.SLAService
+	businessHours  *services.BusinessHours
+	autoReplies    *services.AutoReplyService
+	menu           *services.MenuService
+	csat           *services.CSATService
+	catalog        *i18n.Catalog
+	broadcasts     *services.BroadcastDrafts
+	contentFilter  *services.ContentFilter
+	moderation     *services.ModerationQueue
+	floodGuard     *services.FloodGuard
+	policy         *services.PolicyEngine
+
+	cardMu          sync.Mutex
+	cardRefreshedAt map[int64]time.Time
 }
 
 func NewHandlers(
@@ -33,6 +53,20 @@ func NewHandlers(
 	forumService *services.ForumService,
 	rateLimiter *services.RateLimiter,
 	captchaService *services.CaptchaService,
+	transcripts *services.TranscriptService,
+	assignments *services.AssignmentService,
+	collisions *services.CollisionDetector,
+	sla *services.SLAService,
+	businessHours *services.BusinessHours,
+	autoReplies *services.AutoReplyService,
+	menu *services.MenuService,
+	csat *services.CSATService,
+	catalog *i18n.Catalog,
+	broadcasts *services.BroadcastDrafts,
+	contentFilter *services.ContentFilter,
+	moderation *services.ModerationQueue,
+	floodGuard *services.FloodGuard,
+	policy *services.PolicyEngine,
 ) *Handlers {
 	return &Handlers{
 		bot:            bot,
@@ -42,6 +76,22 @@ func NewHandlers(
 		forumService:   forumService,
 		rateLimiter:    rateLimiter,
 		captchaService: captchaService,
+		transcripts:    transcripts,
+		assignments:    assignments,
+		collisions:     collisions,
+		sla:            sla,
+		businessHours:  businessHours,
+		autoReplies:    autoReplies,
+		menu:           menu,
+		csat:           csat,
+		catalog:        catalog,
+		broadcasts:     broadcasts,
+		contentFilter:  contentFilter,
+		moderation:     moderation,
+		floodGuard:     floodGuard,
+		policy:         policy,
+
+		cardRefreshedAt: make(map[int64]time.Time),
 	}
 }
 
@@ -71,6 +121,12 @@ func (h *Handlers) handleMessage(ctx context.Context, message *models.Message) {
 	userID := message.From.ID
 	chatID := message.Chat.ID
 
+	if h.config.HasAdminGroup() && chatID == h.config.AdminGroupID && h.config.IsAdminUser(userID) {
+		if err := h.assignments.RecordAgent(message.From); err != nil {
+			log.Printf("Error recording agent %d: %v", userID, err)
+		}
+	}
+
 	if isCommand(message) {
 		h.handleCommand(ctx, message)
 		return
@@ -87,7 +143,7 @@ func (h *Handlers) handleMessage(ctx context.Context, message *models.Message) {
 
 	if message.Chat.Type == "private" {
 		if h.config.CaptchaEnabled && !h.db.IsUserVerified(userID) {
-			h.sendCaptchaChallenge(ctx, message.Chat.ID, userID)
+			h.sendCaptchaChallenge(ctx, message.Chat.ID, userID, h.senderLocale(message.From))
 			return
 		}
 		h.handleUserMessage(ctx, message)
@@ -111,6 +167,18 @@ func (h *Handlers) handleCallbackQuery(ctx context.Context, callbackQuery *model
 	switch {
 	case strings.HasPrefix(data, "captcha_"):
 		h.handleCaptchaCallback(ctx, callbackQuery)
+	case strings.HasPrefix(data, "card_"):
+		h.handleCardCallback(ctx, callbackQuery)
+	case strings.HasPrefix(data, "collision_"):
+		h.handleCollisionCallback(ctx, callbackQuery)
+	case strings.HasPrefix(data, "menu_"):
+		h.handleMenuCallback(ctx, callbackQuery)
+	case strings.HasPrefix(data, languageCallbackPrefix):
+		h.handleLanguageCallback(ctx, callbackQuery)
+	case strings.HasPrefix(data, services.CSATCallbackPrefix):
+		h.handleCSATCallback(ctx, callbackQuery)
+	case strings.HasPrefix(data, services.ReviewCallbackPrefix):
+		h.handleReviewCallback(ctx, callbackQuery)
 	default:
 		h.bot.AnswerCallbackQuery(ctx, &tgbot.AnswerCallbackQueryParams{
 			CallbackQueryID: callbackQuery.ID,
@@ -124,36 +192,169 @@ func (h *Handlers) handleCommand(ctx context.Context, message *models.Message) {
 	args := extractCommandArgs(message)
 	userID := message.From.ID
 	chatID := message.Chat.ID
+	denied := h.catalog.T(h.senderLocale(message.From), "common.no_permission")
 
 	switch command {
 	case "start":
 		h.handleStartCommand(ctx, message)
+	case "language":
+		h.handleLanguageCommand(ctx, message)
 	case "clear":
 		if h.config.IsAdminUser(userID) {
 			h.handleClearCommand(ctx, message, args)
 		} else {
-			h.sendMessage(ctx, chatID, "❌ 您没有权限使用此命令")
+			h.sendMessage(ctx, chatID, denied)
 		}
 	case "broadcast":
 		if h.config.IsAdminUser(userID) {
-			h.handleBroadcastCommand(ctx, message)
+			h.handleBroadcastCommand(ctx, message, args)
 		} else {
-			h.sendMessage(ctx, chatID, "❌ 您没有权限使用此命令")
+			h.sendMessage(ctx, chatID, denied)
 		}
 	case "stats":
 		if h.config.IsAdminUser(userID) {
 			h.handleStatsCommand(ctx, message)
 		} else {
-			h.sendMessage(ctx, chatID, "❌ 您没有权限使用此命令")
+			h.sendMessage(ctx, chatID, denied)
 		}
 	case "reset":
 		if h.config.IsAdminUser(userID) {
 			h.handleResetCommand(ctx, message, args)
 		} else {
-			h.sendMessage(ctx, chatID, "❌ 您没有权限使用此命令")
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "export":
+		if h.config.IsAdminUser(userID) {
+			h.handleExportCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "search":
+		if h.config.IsAdminUser(userID) {
+			h.handleSearchCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "whois":
+		if h.config.IsAdminUser(userID) {
+			h.handleWhoisCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "tag":
+		if h.config.IsAdminUser(userID) {
+			h.handleTagCommand(ctx, message, args, false)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "untag":
+		if h.config.IsAdminUser(userID) {
+			h.handleTagCommand(ctx, message, args, true)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "mute":
+		if h.config.IsAdminUser(userID) {
+			h.handleMuteCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "unmute":
+		if h.config.IsAdminUser(userID) {
+			h.handleUnmuteCommand(ctx, message)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "shadowban":
+		if h.config.IsAdminUser(userID) {
+			h.handleShadowBanCommand(ctx, message, args, true)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "unshadowban":
+		if h.config.IsAdminUser(userID) {
+			h.handleShadowBanCommand(ctx, message, args, false)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "trust":
+		if h.config.IsAdminUser(userID) {
+			h.handleTrustCommand(ctx, message, args, true)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "untrust":
+		if h.config.IsAdminUser(userID) {
+			h.handleTrustCommand(ctx, message, args, false)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "set":
+		if h.config.IsAdminUser(userID) {
+			h.handleSetCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "users":
+		if h.config.IsAdminUser(userID) {
+			h.handleUsersCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "assign":
+		if h.config.IsAdminUser(userID) {
+			h.handleAssignCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "unassign":
+		if h.config.IsAdminUser(userID) {
+			h.handleUnassignCommand(ctx, message)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "mine":
+		if h.config.IsAdminUser(userID) {
+			h.handleMineCommand(ctx, message)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "online":
+		if h.config.IsAdminUser(userID) {
+			h.handleAvailabilityCommand(ctx, message, true)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "offline":
+		if h.config.IsAdminUser(userID) {
+			h.handleAvailabilityCommand(ctx, message, false)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "rule":
+		if h.config.IsAdminUser(userID) {
+			h.handleRuleCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "filter":
+		if h.config.IsAdminUser(userID) {
+			h.handleFilterCommand(ctx, message, args)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
+		}
+	case "reconcile":
+		if h.config.IsAdminUser(userID) {
+			h.handleReconcileCommand(ctx, message)
+		} else {
+			h.sendMessage(ctx, chatID, denied)
 		}
 	default:
-		h.sendMessage(ctx, chatID, "❓ 未知命令。使用 /start 开始使用机器人。")
+		if h.config.IsAdminUser(userID) {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("common.unknown_command"))
+		} else {
+			h.sendMessage(ctx, chatID, h.catalog.T(h.senderLocale(message.From), "common.unknown_command"))
+		}
 	}
 }
 
@@ -162,34 +363,32 @@ func (h *Handlers) handleStartCommand(ctx context.Context, message *models.Messa
 	chatID := message.Chat.ID
 
 	if h.config.HasAdminGroup() && chatID == h.config.AdminGroupID {
-		h.sendMessage(ctx, chatID, "✅ 机器人在管理群组中正常运行")
+		h.sendMessage(ctx, chatID, h.catalog.Admin("start.admin_group_ok"))
 		return
 	}
 
 	if message.Chat.Type == "private" {
-		user := &dbmodels.User{
-			UserID:    userID,
-			FirstName: message.From.FirstName,
-			LastName:  message.From.LastName,
-			Username:  message.From.Username,
-			IsPremium: message.From.IsPremium,
-		}
-
-		if existing, err := h.db.GetUser(userID); err == nil {
-			user.Verified = existing.Verified
-			user.MessageThreadID = existing.MessageThreadID
+		user, err := h.db.GetUser(userID)
+		if err != nil {
+			user = &dbmodels.User{UserID: userID}
 		}
+		user.FirstName = message.From.FirstName
+		user.LastName = message.From.LastName
+		user.Username = message.From.Username
+		user.IsPremium = message.From.IsPremium
+		user.LanguageCode = message.From.LanguageCode
 
 		if err := h.db.CreateOrUpdateUser(user); err != nil {
 			log.Printf("Error updating user: %v", err)
 		}
 
+		locale := h.userLocale(user)
 		if h.config.CaptchaEnabled && !user.Verified {
-			h.sendCaptchaChallenge(ctx, chatID, userID)
+			h.sendCaptchaChallenge(ctx, chatID, userID, locale)
 			return
 		}
 
-		h.sendMessage(ctx, chatID, h.config.WelcomeMessage)
+		h.sendWelcome(ctx, chatID, locale)
 	}
 }
 
@@ -197,24 +396,29 @@ func (h *Handlers) handleUserMessage(ctx context.Context, message *models.Messag
 	userID := message.From.ID
 	chatID := message.Chat.ID
 
-	isMediaGroup := message.MediaGroupID != ""
+	if h.csat.IsEnabled() && h.handleCSATComment(ctx, message) {
+		return
+	}
 
-	if h.rateLimiter.IsEnabled() && !isMediaGroup {
-		canSend, waitTime := h.rateLimiter.CheckAndRecord(userID)
-		if !canSend {
-			h.sendMessage(ctx, chatID, h.rateLimiter.FormatCooldownMessage(waitTime))
-			return
-		}
+	if h.db.IsUserShadowBanned(userID) {
+		h.archiveMessage(ctx, message)
+		return
+	}
+
+	if mute, err := h.db.GetActiveMute(userID); err == nil {
+		h.handleMutedMessage(ctx, message, mute)
+		return
 	}
 
 	user, err := h.db.GetUser(userID)
 	if err != nil {
 		user = &dbmodels.User{
-			UserID:    userID,
-			FirstName: message.From.FirstName,
-			LastName:  message.From.LastName,
-			Username:  message.From.Username,
-			IsPremium: message.From.IsPremium,
+			UserID:       userID,
+			FirstName:    message.From.FirstName,
+			LastName:     message.From.LastName,
+			Username:     message.From.Username,
+			IsPremium:    message.From.IsPremium,
+			LanguageCode: message.From.LanguageCode,
 		}
 		if err := h.db.CreateOrUpdateUser(user); err != nil {
 			log.Printf("Error creating user: %v", err)
@@ -222,8 +426,56 @@ func (h *Handlers) handleUserMessage(ctx context.Context, message *models.Messag
 		}
 	}
 
-	if h.config.HasAdminGroup() {
-		h.forwardUserMessageToAdmin(ctx, message, user)
+	if h.checkFlood(ctx, message, user) {
+		return
+	}
+
+	isMediaGroup := message.MediaGroupID != ""
+
+	if h.rateLimiter.IsEnabled() && !isMediaGroup {
+		canSend, waitTime := h.rateLimiter.CheckAndRecord(userID)
+		if !canSend {
+			h.sendMessage(ctx, chatID, h.rateLimiter.FormatCooldownMessage(h.userLocale(user), waitTime))
+			return
+		}
+	}
+
+	if err := h.db.TouchUserActivity(userID, message.From.LanguageCode); err != nil {
+		log.Printf("Error updating user activity: %v", err)
+	} else if updated, err := h.db.GetUser(userID); err == nil {
+		user = updated
+	}
+
+	if filterRule := h.contentFilter.Check(userID, message); filterRule != nil {
+		h.handleFilterHit(ctx, message, user, filterRule)
+		return
+	}
+
+	if feature := h.policy.Check(user, message); feature != "" {
+		h.handlePolicyHit(ctx, message, user, feature)
+		return
+	}
+
+	rule := h.autoReplies.Match(messageText(message))
+	if rule != nil {
+		h.sendAutoReply(ctx, chatID, h.autoReplies.Localize(rule, h.userLocale(user)))
+	}
+
+	if rule == nil || rule.Forward {
+		if h.config.HasAdminGroup() {
+			h.forwardUserMessageToAdmin(ctx, message, user, false)
+			h.noteMenuPath(ctx, user)
+			if err := h.sla.RecordInbound(userID); err != nil {
+				log.Printf("Error recording SLA wait for user %d: %v", userID, err)
+			}
+			if rule != nil {
+				h.noteAutoReply(ctx, userID, rule)
+			}
+		}
+
+		if h.businessHours.IsEnabled() {
+			h.handleOutOfHours(ctx, chatID, userID)
+		}
 	}
 
 	go func() {
@@ -233,9 +485,31 @@ func (h *Handlers) handleUserMessage(ctx context.Context, message *models.Messag
 	}()
 }
 
-// forwardUserMessageToAdmin forwards a user message to the admin group.
+// handleOutOfHours sends the out-of-office auto-reply once per closed period
+// and marks the user's topic so agents know the message arrived after hours.
+func (h *Handlers) handleOutOfHours(ctx context.Context, chatID int64, userID int64) {
+	nextOpen, ok := h.businessHours.ClaimOutOfHoursNotice(userID, time.Now())
+	if !ok {
+		return
+	}
+
+	user, err := h.db.GetUser(userID)
+	if err != nil {
+		return
+	}
+	h.sendMessage(ctx, chatID, h.businessHours.FormatAutoReply(h.userLocale(user), nextOpen))
+
+	if !h.config.HasAdminGroup() || user.MessageThreadID == 0 {
+		return
+	}
+	h.sendThreadMessage(ctx, h.config.AdminGroupID, user.MessageThreadID,
+		h.catalog.Admin("hours.topic_note", h.businessHours.FormatOpening(h.catalog.AdminLocale(), nextOpen)))
+}
+
+// forwardUserMessageToAdmin forwards a user message to the admin group,
+// without a notification if silent is set.
 // Uses a retry loop (max 1 retry) to handle deleted topics.
-func (h *Handlers) forwardUserMessageToAdmin(ctx context.Context, message *models.Message, user *dbmodels.User) {
+func (h *Handlers) forwardUserMessageToAdmin(ctx context.Context, message *models.Message, user *dbmodels.User, silent bool) {
 	const maxAttempts = 2
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
@@ -246,28 +520,33 @@ func (h *Handlers) forwardUserMessageToAdmin(ctx context.Context, message *model
 		}
 
 		if isNewTopic {
-			userInfoMsg, err := h.messageService.SendUserInfoMessage(ctx, h.bot, user, h.config.AdminGroupID, threadID)
-			if err != nil {
-				log.Printf("Error sending user info message: %v", err)
-			} else {
-				if err := h.messageService.CreateMessageMap(0, userInfoMsg.ID, user.UserID); err != nil {
-					log.Printf("Error creating user info message mapping: %v", err)
-				}
+			if err := h.sla.ResetConversation(user.UserID); err != nil {
+				log.Printf("Error resetting SLA for user %d: %v", user.UserID, err)
 			}
+			h.autoAssign(ctx, user, threadID)
+			h.sendUserCard(ctx, user, threadID)
+		} else {
+			h.touchUserCard(ctx, user.UserID)
 		}
 
 		if message.MediaGroupID != "" {
-			h.messageService.HandleMediaGroup(ctx, h.bot, message, h.config.AdminGroupID, threadID)
+			h.messageService.HandleMediaGroup(ctx, h.bot, message, h.config.AdminGroupID, threadID, silent)
+			h.recordTranscript(dbmodels.DirectionInbound, user.UserID, message, message.ID, 0, threadID)
+			h.countRelayedMessage(user.UserID)
 			return
 		}
 
-		forwardedMsg, err := h.messageService.ForwardMessageToGroup(ctx, h.bot, message, h.config.AdminGroupID, threadID)
+		forwardedMsg, err := h.messageService.ForwardMessageToGroup(ctx, h.bot, message, h.config.AdminGroupID, threadID, silent)
 		if err != nil {
-			if strings.Contains(err.Error(), "message thread not found") && attempt < maxAttempts-1 {
+			if services.IsThreadNotFoundError(err) && attempt < maxAttempts-1 {
 				log.Printf("Thread %d not found for user %d, resetting and retrying", threadID, user.UserID)
 
-				if resetErr := h.forumService.ResetUserThreadID(user.UserID); resetErr != nil {
-					log.Printf("Error resetting user thread ID: %v", resetErr)
+				banned, resetErr := h.forumService.HandleTopicDeleted(user.UserID)
+				if resetErr != nil {
+					log.Printf("Error handling deleted topic: %v", resetErr)
+					return
+				}
+				if banned {
 					return
 				}
 
@@ -286,18 +565,68 @@ func (h *Handlers) forwardUserMessageToAdmin(ctx context.Context, message *model
 
 		if err := h.messageService.CreateMessageMap(message.ID, forwardedMsg.ID, user.UserID); err != nil {
 			log.Printf("Error creating message map: %v", err)
+		} else {
+			h.floodGuard.Remember(user.UserID, message)
 		}
+		h.recordTranscript(dbmodels.DirectionInbound, user.UserID, message, message.ID, forwardedMsg.ID, threadID)
+		h.countRelayedMessage(user.UserID)
 		return
 	}
 }
 
+// countRelayedMessage adds a message that reached the agents to the user's
+// message count, which the new-user policy relies on
+func (h *Handlers) countRelayedMessage(userID int64) {
+	if err := h.db.IncrementUserMessageCount(userID); err != nil {
+		log.Printf("Error counting message of user %d: %v", userID, err)
+	}
+}
+
 func (h *Handlers) handleAdminGroupMessage(ctx context.Context, message *models.Message) {
+	if h.handleForumServiceMessage(message) {
+		return
+	}
+
 	if message.ReplyToMessage != nil {
 		h.handleAdminReply(ctx, message)
 		return
 	}
 }
 
+// handleForumServiceMessage keeps ForumStatus in sync with topic changes made
+// from the Telegram UI. Returns true if the message was a topic service message.
+func (h *Handlers) handleForumServiceMessage(message *models.Message) bool {
+	if message.ForumTopicClosed == nil && message.ForumTopicReopened == nil && message.ForumTopicEdited == nil {
+		return false
+	}
+
+	threadID := message.MessageThreadID
+	if threadID == 0 {
+		return true
+	}
+
+	if _, err := h.forumService.GetUserByThreadID(threadID); err != nil {
+		return true
+	}
+
+	switch {
+	case message.ForumTopicClosed != nil:
+		if err := h.forumService.HandleForumStatusChange(threadID, "closed"); err != nil {
+			log.Printf("Error marking topic %d closed: %v", threadID, err)
+		}
+	case message.ForumTopicReopened != nil:
+		if err := h.forumService.HandleForumStatusChange(threadID, "opened"); err != nil {
+			log.Printf("Error marking topic %d opened: %v", threadID, err)
+		}
+	case message.ForumTopicEdited != nil && message.ForumTopicEdited.Name != "":
+		if err := h.forumService.HandleTopicRenamed(threadID, message.ForumTopicEdited.Name); err != nil {
+			log.Printf("Error renaming topic %d: %v", threadID, err)
+		}
+	}
+
+	return true
+}
+
 func (h *Handlers) handleAdminReply(ctx context.Context, message *models.Message) {
 	replyToMessage := message.ReplyToMessage
 	var user *dbmodels.User
@@ -325,6 +654,22 @@ func (h *Handlers) handleAdminReply(ctx context.Context, message *models.Message
 		}
 	}
 
+	threadID := message.MessageThreadID
+	if h.collisions.IsSoftLock() {
+		if holder, locked := h.softLockHolder(threadID, user, message.From.ID); locked {
+			h.holdAdminReply(ctx, message, user, holder)
+			return
+		}
+	} else if otherAgent, collided := h.collisions.Check(threadID, message.From.ID); collided {
+		h.sendThreadMessage(ctx, message.Chat.ID, threadID,
+			h.catalog.Admin("collision.warning", html.EscapeString(otherAgent)))
+	}
+
+	h.relayAdminReply(ctx, message, user)
+}
+
+// relayAdminReply sends an admin's reply to the user and records it.
+func (h *Handlers) relayAdminReply(ctx context.Context, message *models.Message, user *dbmodels.User) {
 	forwardedMsg, err := h.messageService.ForwardMessageToUser(ctx, h.bot, message, user.UserID)
 	if err != nil {
 		log.Printf("Error forwarding admin reply: %v", err)
@@ -334,8 +679,14 @@ func (h *Handlers) handleAdminReply(ctx context.Context, message *models.Message
 	if err := h.messageService.CreateMessageMap(forwardedMsg.ID, message.ID, user.UserID); err != nil {
 		log.Printf("Error creating reverse message map: %v", err)
 	}
+	h.recordTranscript(dbmodels.DirectionOutbound, user.UserID, message, forwardedMsg.ID, message.ID, message.MessageThreadID)
 
 	threadID := message.MessageThreadID
+	h.collisions.Record(threadID, message.From.ID, message.From.FirstName)
+	if err := h.sla.RecordResponse(user.UserID, message.From.ID); err != nil {
+		log.Printf("Error recording SLA response for user %d: %v", user.UserID, err)
+	}
+
 	if threadID != 0 && h.forumService.IsForumTopicClosed(threadID) {
 		if err := h.forumService.ReopenForumTopic(ctx, threadID); err != nil {
 			log.Printf("Error reopening forum topic: %v", err)
@@ -343,20 +694,26 @@ func (h *Handlers) handleAdminReply(ctx context.Context, message *models.Message
 	}
 }
 
+func (h *Handlers) recordTranscript(direction string, userID int64, message *models.Message, userChatMessageID int, groupChatMessageID int, threadID int) {
+	if err := h.transcripts.RecordMessage(direction, userID, message, userChatMessageID, groupChatMessageID, threadID); err != nil {
+		log.Printf("Error recording transcript message: %v", err)
+	}
+}
+
 // sendCaptchaChallenge sends a new CAPTCHA challenge to the user.
 // Skips if a challenge is already active or the user is in cooldown.
-func (h *Handlers) sendCaptchaChallenge(ctx context.Context, chatID int64, userID int64) {
+func (h *Handlers) sendCaptchaChallenge(ctx context.Context, chatID int64, userID int64, locale string) {
 	if h.captchaService.HasActiveChallenge(userID) {
 		return
 	}
 
 	if remaining := h.captchaService.GetCooldownRemaining(userID); remaining > 0 {
 		secs := int(remaining.Seconds()) + 1
-		h.sendMessage(ctx, chatID, fmt.Sprintf("⏰ 验证失败冷却中，请 %d 秒后再试", secs))
+		h.sendMessage(ctx, chatID, h.catalog.T(locale, "captcha.cooldown", secs))
 		return
 	}
 
-	question, keyboard := h.captchaService.GenerateChallenge(userID)
+	question, keyboard := h.captchaService.GenerateChallenge(userID, locale)
 	msg, err := h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
 		ChatID:      chatID,
 		Text:        question,
@@ -371,6 +728,7 @@ func (h *Handlers) sendCaptchaChallenge(ctx context.Context, chatID int64, userI
 
 func (h *Handlers) handleCaptchaCallback(ctx context.Context, cq *models.CallbackQuery) {
 	userID := cq.From.ID
+	locale := h.senderLocale(&cq.From)
 
 	answerStr := strings.TrimPrefix(cq.Data, "captcha_")
 	answer, err := strconv.Atoi(answerStr)
@@ -385,7 +743,7 @@ func (h *Handlers) handleCaptchaCallback(ctx context.Context, cq *models.Callbac
 	if correct {
 		h.bot.AnswerCallbackQuery(ctx, &tgbot.AnswerCallbackQueryParams{
 			CallbackQueryID: cq.ID,
-			Text:            "✅ 验证通过！",
+			Text:            h.catalog.T(locale, "captcha.passed"),
 		})
 
 		if hasMsg {
@@ -398,8 +756,9 @@ func (h *Handlers) handleCaptchaCallback(ctx context.Context, cq *models.Callbac
 		if err := h.db.SetUserVerified(userID, true); err != nil {
 			log.Printf("Error setting user verified: %v", err)
 		}
+		h.refreshUserCard(ctx, userID)
 
-		h.sendMessage(ctx, chatID, h.config.WelcomeMessage)
+		h.sendWelcome(ctx, chatID, locale)
 		return
 	}
 
@@ -408,7 +767,7 @@ func (h *Handlers) handleCaptchaCallback(ctx context.Context, cq *models.Callbac
 
 	h.bot.AnswerCallbackQuery(ctx, &tgbot.AnswerCallbackQueryParams{
 		CallbackQueryID: cq.ID,
-		Text:            fmt.Sprintf("❌ 回答错误，请 %d 秒后重试", secs),
+		Text:            h.catalog.T(locale, "captcha.wrong", secs),
 		ShowAlert:       true,
 	})
 
@@ -450,6 +809,14 @@ func extractCommand(msg *models.Message) string {
 	return cmd
 }
 
+// messageText returns the text or caption of a message
+func messageText(msg *models.Message) string {
+	if msg.Text != "" {
+		return msg.Text
+	}
+	return msg.Caption
+}
+
 func extractCommandArgs(msg *models.Message) string {
 	if !isCommand(msg) {
 		return ""
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"html"
+	"log"
+	"strings"
+	"telegram-communication-bot/internal/i18n"
+	dbmodels "telegram-communication-bot/internal/models"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// languageCallbackPrefix starts language picker callback data: lang_<locale>.
+// An empty locale switches back to the Telegram client language.
+const languageCallbackPrefix = "lang_"
+
+// handleLanguageCommand shows the user an inline keyboard of the available locales
+func (h *Handlers) handleLanguageCommand(ctx context.Context, message *models.Message) {
+	chatID := message.Chat.ID
+	if message.Chat.Type != "private" {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("language.private_only"))
+		return
+	}
+
+	user, err := h.db.GetUser(message.From.ID)
+	if err != nil {
+		user = &dbmodels.User{
+			UserID:       message.From.ID,
+			FirstName:    message.From.FirstName,
+			LastName:     message.From.LastName,
+			Username:     message.From.Username,
+			IsPremium:    message.From.IsPremium,
+			LanguageCode: message.From.LanguageCode,
+		}
+		if err := h.db.CreateOrUpdateUser(user); err != nil {
+			log.Printf("Error creating user: %v", err)
+			return
+		}
+	}
+
+	locale := h.userLocale(user)
+	_, err = h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        h.catalog.T(locale, "language.prompt", h.catalog.T(locale, i18n.NameKey)),
+		ReplyMarkup: h.languageKeyboard(user, locale),
+	})
+	if err != nil {
+		log.Printf("Error sending language picker: %v", err)
+	}
+}
+
+// languageKeyboard lists every loaded locale under its own name, plus an
+// option to follow the Telegram client language. The current choice is ticked.
+func (h *Handlers) languageKeyboard(user *dbmodels.User, locale string) *models.InlineKeyboardMarkup {
+	var rows [][]models.InlineKeyboardButton
+	for _, available := range h.catalog.Locales() {
+		label := h.catalog.T(available, i18n.NameKey)
+		if user.Locale == available {
+			label = "✅ " + label
+		}
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: label, CallbackData: languageCallbackPrefix + available},
+		})
+	}
+
+	auto := h.catalog.T(locale, "language.auto")
+	if user.Locale == "" {
+		auto = "✅ " + auto
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: auto, CallbackData: languageCallbackPrefix},
+	})
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// handleLanguageCallback stores the picked locale and confirms in that language
+func (h *Handlers) handleLanguageCallback(ctx context.Context, cq *models.CallbackQuery) {
+	choice := strings.TrimPrefix(cq.Data, languageCallbackPrefix)
+	if choice != "" && !h.catalog.Has(choice) {
+		h.answerCallback(ctx, cq.ID, h.catalog.T(h.senderLocale(&cq.From), "language.unavailable"), true)
+		return
+	}
+
+	if err := h.db.SetUserLocale(cq.From.ID, choice); err != nil {
+		log.Printf("Error saving locale for user %d: %v", cq.From.ID, err)
+		h.answerCallback(ctx, cq.ID, h.catalog.T(h.senderLocale(&cq.From), "language.failed"), true)
+		return
+	}
+
+	locale := h.senderLocale(&cq.From)
+	h.answerCallback(ctx, cq.ID, "", false)
+	if msg := cq.Message.Message; msg != nil {
+		h.editMenuMessage(ctx, msg, h.catalog.T(locale, "language.changed", html.EscapeString(h.catalog.T(locale, i18n.NameKey))), nil)
+	}
+}
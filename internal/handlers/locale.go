@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	dbmodels "telegram-communication-bot/internal/models"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// userLocale returns the catalog locale for a stored user: the one picked
+// with /language if it is still available, otherwise the client language
+func (h *Handlers) userLocale(user *dbmodels.User) string {
+	if user.Locale != "" && h.catalog.Has(user.Locale) {
+		return h.catalog.Resolve(user.Locale)
+	}
+	return h.catalog.Resolve(user.LanguageCode)
+}
+
+// senderLocale returns the catalog locale for the sender of an update,
+// honouring their stored choice if they are a known user
+func (h *Handlers) senderLocale(from *models.User) string {
+	if from == nil {
+		return h.catalog.DefaultLocale()
+	}
+	if user, err := h.db.GetUser(from.ID); err == nil {
+		return h.userLocale(user)
+	}
+	return h.catalog.Resolve(from.LanguageCode)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"html"
+	"log"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// welcomeText returns the configured welcome message, or the localized default
+func (h *Handlers) welcomeText(locale string) string {
+	if h.config.WelcomeMessage != "" {
+		return h.config.WelcomeMessage
+	}
+	return h.catalog.T(locale, "welcome")
+}
+
+// sendWelcome greets the user with the self-service menu if one is
+// configured, falling back to the plain welcome message.
+func (h *Handlers) sendWelcome(ctx context.Context, chatID int64, locale string) {
+	root, ok := h.menu.Find("")
+	if !ok {
+		h.sendMessage(ctx, chatID, h.welcomeText(locale))
+		return
+	}
+
+	text := root.Text
+	if text == "" {
+		text = html.EscapeString(h.welcomeText(locale))
+	}
+
+	_, err := h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: h.menu.Keyboard(locale, "", root),
+	})
+	if err != nil {
+		log.Printf("Error sending welcome menu: %v", err)
+	}
+}
+
+// handleMenuCallback navigates the self-service menu in place
+func (h *Handlers) handleMenuCallback(ctx context.Context, cq *models.CallbackQuery) {
+	msg := cq.Message.Message
+	if msg == nil {
+		h.answerCallback(ctx, cq.ID, "", false)
+		return
+	}
+	locale := h.senderLocale(&cq.From)
+
+	if path, ok := strings.CutPrefix(cq.Data, services.MenuCallbackHuman); ok {
+		if err := h.db.SetUserMenuPath(cq.From.ID, path); err != nil {
+			log.Printf("Error saving menu path for user %d: %v", cq.From.ID, err)
+		}
+		h.answerCallback(ctx, cq.ID, "", false)
+		h.editMenuMessage(ctx, msg, h.catalog.T(locale, "menu.human_prompt"), nil)
+		return
+	}
+
+	path := strings.TrimPrefix(cq.Data, services.MenuCallbackOpen)
+	node, ok := h.menu.Find(path)
+	if !ok {
+		h.answerCallback(ctx, cq.ID, h.catalog.T(locale, "menu.outdated"), true)
+		return
+	}
+
+	if err := h.db.SetUserMenuPath(cq.From.ID, path); err != nil {
+		log.Printf("Error saving menu path for user %d: %v", cq.From.ID, err)
+	}
+	h.answerCallback(ctx, cq.ID, "", false)
+
+	text := node.Text
+	if text == "" {
+		text = "<b>" + html.EscapeString(node.Label) + "</b>"
+		if path == "" {
+			text = html.EscapeString(h.welcomeText(locale))
+		}
+	}
+	h.editMenuMessage(ctx, msg, text, h.menu.Keyboard(locale, path, node))
+}
+
+func (h *Handlers) editMenuMessage(ctx context.Context, msg *models.Message, text string, keyboard *models.InlineKeyboardMarkup) {
+	params := &tgbot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	}
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+	if _, err := h.bot.EditMessageText(ctx, params); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		log.Printf("Error updating menu message: %v", err)
+	}
+}
+
+// noteMenuPath posts the menu path the user followed into their topic once
+// they reach an agent, then clears it.
+func (h *Handlers) noteMenuPath(ctx context.Context, user *dbmodels.User) {
+	if user.LastMenuPath == "" {
+		return
+	}
+	if err := h.db.SetUserMenuPath(user.UserID, ""); err != nil {
+		log.Printf("Error clearing menu path for user %d: %v", user.UserID, err)
+	}
+
+	breadcrumb := h.menu.Breadcrumb(user.LastMenuPath)
+	if breadcrumb == "" {
+		return
+	}
+	updated, err := h.db.GetUser(user.UserID)
+	if err != nil || updated.MessageThreadID == 0 {
+		return
+	}
+	h.sendThreadMessage(ctx, h.config.AdminGroupID, updated.MessageThreadID,
+		h.catalog.Admin("menu.topic_note", html.EscapeString(breadcrumb)))
+}
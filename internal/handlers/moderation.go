@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html"
+	"log"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// holdForReview keeps a user message back and queues it in the review topic.
+// If it cannot be queued the message is forwarded normally so it is not lost.
+// Returns whether the message was held, i.e. whether the user should be told.
+func (h *Handlers) holdForReview(ctx context.Context, message *models.Message, user *dbmodels.User, kind string, reason string) bool {
+	held, err := h.moderation.Hold(ctx, message, user, kind, reason)
+	if err != nil {
+		log.Printf("Error queueing message %d from user %d for review, forwarding instead: %v", message.ID, user.UserID, err)
+		if h.config.HasAdminGroup() {
+			fallback := *message
+			fallback.MediaGroupID = ""
+			h.forwardUserMessageToAdmin(ctx, &fallback, user, false)
+			if err := h.sla.RecordInbound(user.UserID); err != nil {
+				log.Printf("Error recording SLA wait for user %d: %v", user.UserID, err)
+			}
+		}
+		return false
+	}
+	log.Printf("Message %d from user %d held for review as #%d", message.ID, user.UserID, held.ID)
+	return true
+}
+
+// handleReviewCallback applies a moderator's decision on a held message.
+// Approved messages take the regular path into the user's topic; approving a
+// message held by the new-user policy also trusts its sender.
+func (h *Handlers) handleReviewCallback(ctx context.Context, cq *models.CallbackQuery) {
+	if !h.config.IsAdminUser(cq.From.ID) {
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.no_permission"), true)
+		return
+	}
+
+	status, id, ok := services.ParseReviewCallback(cq.Data)
+	if !ok {
+		h.answerCallback(ctx, cq.ID, "", false)
+		return
+	}
+
+	held, original, err := h.moderation.Resolve(id, status, cq.From.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrAlreadyReviewed) {
+			h.answerCallback(ctx, cq.ID, h.catalog.Admin("review.already_reviewed"), true)
+			return
+		}
+		log.Printf("Error resolving held message %d: %v", id, err)
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("review.failed"), true)
+		return
+	}
+
+	user, err := h.db.GetUser(held.UserID)
+	if err != nil {
+		h.answerCallback(ctx, cq.ID, h.catalog.Admin("card.user_not_found"), true)
+		return
+	}
+
+	outcome := h.catalog.Admin("review.outcome_"+status, html.EscapeString(cq.From.FirstName))
+
+	switch status {
+	case dbmodels.HeldStatusApproved:
+		if held.Kind == dbmodels.HeldKindPolicy && !user.Trusted {
+			if err := h.db.SetUserTrusted(user.UserID, true); err != nil {
+				log.Printf("Error trusting user %d: %v", user.UserID, err)
+			} else {
+				user.Trusted = true
+				outcome += h.catalog.Admin("review.outcome_trusted")
+			}
+		}
+		if original != nil && h.config.HasAdminGroup() {
+			// Album siblings were never held, so the item goes on its own
+			original.MediaGroupID = ""
+			h.forwardUserMessageToAdmin(ctx, original, user, false)
+			if err := h.sla.RecordInbound(user.UserID); err != nil {
+				log.Printf("Error recording SLA wait for user %d: %v", user.UserID, err)
+			}
+		}
+
+	case dbmodels.HeldStatusRejected:
+		h.sendMessage(ctx, user.UserID, h.catalog.T(h.userLocale(user), "review.rejected_user"))
+
+	case dbmodels.HeldStatusBanned:
+		if err := h.banUser(user.UserID, "Banned from the review queue"); err != nil {
+			log.Printf("Error banning user %d: %v", user.UserID, err)
+		}
+		h.refreshUserCard(ctx, user.UserID)
+	}
+
+	if msg := cq.Message.Message; msg != nil {
+		text := h.moderation.CardText(held, user) + outcome
+		h.editMenuMessage(ctx, msg, text, nil)
+	}
+	h.answerCallback(ctx, cq.ID, h.catalog.Admin("review.done_"+status), false)
+}
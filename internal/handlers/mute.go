@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+const muteTimeLayout = "2006-01-02 15:04"
+
+// handleMuteCommand mutes the user of the current topic for a while. Their
+// messages are forwarded without a notification, or with "hold" only stored.
+func (h *Handlers) handleMuteCommand(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	user := h.userFromTopicContext(message)
+	if user == nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("mute.usage"))
+		return
+	}
+
+	durationArg, mode, _ := strings.Cut(strings.TrimSpace(args), " ")
+	duration, err := parseMuteDuration(durationArg)
+	if err != nil {
+		h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("mute.usage"))
+		return
+	}
+
+	forward := true
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+	case "hold":
+		forward = false
+	default:
+		h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("mute.usage"))
+		return
+	}
+
+	mute := &dbmodels.MuteStatus{
+		UserID:     user.UserID,
+		MutedUntil: time.Now().Add(duration),
+		Reason:     "Muted by admin",
+		MutedBy:    message.From.ID,
+		Forward:    forward,
+	}
+	if err := h.db.MuteUser(mute); err != nil {
+		log.Printf("Error muting user %d: %v", user.UserID, err)
+		h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("mute.failed"))
+		return
+	}
+
+	h.refreshUserCard(ctx, user.UserID)
+
+	key := "mute.done_forward"
+	if !forward {
+		key = "mute.done_hold"
+	}
+	h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin(key, mute.MutedUntil.Format(muteTimeLayout)))
+}
+
+// handleUnmuteCommand lifts the mute of the user of the current topic
+func (h *Handlers) handleUnmuteCommand(ctx context.Context, message *models.Message) {
+	chatID := message.Chat.ID
+
+	user := h.userFromTopicContext(message)
+	if user == nil {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("mute.unmute_usage"))
+		return
+	}
+
+	mute, err := h.db.UnmuteUser(user.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("mute.not_muted"))
+			return
+		}
+		log.Printf("Error unmuting user %d: %v", user.UserID, err)
+		h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("mute.failed"))
+		return
+	}
+
+	h.notifyUnmuted(ctx, user, mute)
+	h.sendThreadMessage(ctx, chatID, message.MessageThreadID, h.catalog.Admin("mute.unmuted"))
+}
+
+// handleMutedMessage stores or silently forwards a message from a muted user
+// and tells them once that it will be looked at later. Forwarded messages
+// still go through the content filter and new-user policy.
+func (h *Handlers) handleMutedMessage(ctx context.Context, message *models.Message, mute *dbmodels.MuteStatus) {
+	user, err := h.db.GetUser(message.From.ID)
+	if err != nil {
+		log.Printf("Error loading muted user %d: %v", message.From.ID, err)
+		return
+	}
+	if err := h.db.TouchUserActivity(user.UserID, message.From.LanguageCode); err != nil {
+		log.Printf("Error updating user activity: %v", err)
+	} else if updated, err := h.db.GetUser(user.UserID); err == nil {
+		user = updated
+	}
+
+	if !mute.Notified {
+		h.sendMessage(ctx, message.Chat.ID, h.catalog.T(h.userLocale(user), "mute.notice"))
+		if err := h.db.MarkMuteNotified(user.UserID); err != nil {
+			log.Printf("Error marking mute notice for user %d: %v", user.UserID, err)
+		}
+	}
+
+	if mute.Forward && h.config.HasAdminGroup() {
+		if filterRule := h.contentFilter.Check(user.UserID, message); filterRule != nil {
+			h.handleFilterHit(ctx, message, user, filterRule)
+			return
+		}
+		if feature := h.policy.Check(user, message); feature != "" {
+			h.handlePolicyHit(ctx, message, user, feature)
+			return
+		}
+		h.forwardUserMessageToAdmin(ctx, message, user, true)
+		return
+	}
+	h.recordTranscript(dbmodels.DirectionInbound, user.UserID, message, message.ID, 0, user.MessageThreadID)
+}
+
+// LiftExpiredMutes ends mutes that have run out. Called by the scheduler.
+func (h *Handlers) LiftExpiredMutes(ctx context.Context) {
+	mutes, err := h.db.ExpireMutes(time.Now())
+	if err != nil {
+		log.Printf("Error expiring mutes: %v", err)
+		return
+	}
+
+	for i := range mutes {
+		user, err := h.db.GetUser(mutes[i].UserID)
+		if err != nil {
+			continue
+		}
+		log.Printf("Mute of user %d expired", user.UserID)
+		h.notifyUnmuted(ctx, user, &mutes[i])
+		if h.config.HasAdminGroup() && user.MessageThreadID != 0 {
+			h.sendThreadMessage(ctx, h.config.AdminGroupID, user.MessageThreadID, h.catalog.Admin("mute.expired"))
+		}
+	}
+}
+
+// notifyUnmuted tells a user who knew about their mute that it is over and
+// updates their card
+func (h *Handlers) notifyUnmuted(ctx context.Context, user *dbmodels.User, mute *dbmodels.MuteStatus) {
+	if mute.Notified {
+		h.sendMessage(ctx, user.UserID, h.catalog.T(h.userLocale(user), "mute.lifted"))
+	}
+	h.refreshUserCard(ctx, user.UserID)
+}
+
+// parseMuteDuration accepts Go durations such as 30m or 1h30m and whole
+// days such as 3d
+func parseMuteDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
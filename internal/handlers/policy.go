@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	dbmodels "telegram-communication-bot/internal/models"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// handlePolicyHit holds back a message a new user may not send yet and
+// queues it for review; approving it also trusts the user
+func (h *Handlers) handlePolicyHit(ctx context.Context, message *models.Message, user *dbmodels.User, feature string) {
+	if h.holdForReview(ctx, message, user, dbmodels.HeldKindPolicy,
+		h.catalog.Admin("review.reason_policy", h.catalog.Admin("policy.feature_"+feature))) {
+		h.sendMessage(ctx, message.Chat.ID, h.catalog.T(h.userLocale(user), "policy.restricted"))
+	}
+}
+
+// handleTrustCommand exempts a user from new-user restrictions, or revokes it
+func (h *Handlers) handleTrustCommand(ctx context.Context, message *models.Message, args string, trusted bool) {
+	command := "/trust"
+	if !trusted {
+		command = "/untrust"
+	}
+
+	user, _ := h.targetUserOrReply(ctx, message, args, h.catalog.Admin("policy.trust_usage", command), false)
+	if user == nil {
+		return
+	}
+
+	if err := h.db.SetUserTrusted(user.UserID, trusted); err != nil {
+		log.Printf("Error updating trust for user %d: %v", user.UserID, err)
+		h.sendMessage(ctx, message.Chat.ID, h.catalog.Admin("policy.trust_failed"))
+		return
+	}
+
+	h.refreshUserCard(ctx, user.UserID)
+
+	key := "policy.trusted"
+	if !trusted {
+		key = "policy.untrusted"
+	}
+	h.sendMessage(ctx, message.Chat.ID, h.catalog.Admin(key, user.UserID, user.FirstName))
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"telegram-communication-bot/internal/database"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+	"time"
+	"unicode/utf8"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const searchResultLimit = 10
+
+func (h *Handlers) handleSearchCommand(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	query, filter, err := h.parseSearchArgs(args)
+	if err != nil {
+		h.sendMessage(ctx, chatID, "❌ "+err.Error())
+		return
+	}
+	if query == "" {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("search.usage"))
+		return
+	}
+
+	filter.Limit = searchResultLimit
+	results, err := h.transcripts.Search(query, filter)
+	if err != nil {
+		log.Printf("Error searching transcripts: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("search.failed"))
+		return
+	}
+
+	if len(results) == 0 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("search.no_match"))
+		return
+	}
+
+	_, err = h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:             chatID,
+		MessageThreadID:    message.MessageThreadID,
+		Text:               h.formatSearchResults(query, results),
+		ParseMode:          models.ParseModeHTML,
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: tgbot.True()},
+	})
+	if err != nil {
+		log.Printf("Error sending search results: %v", err)
+	}
+}
+
+// parseSearchArgs splits the /search arguments into free-text terms and
+// user:/from:/to:/dir: filters. user: accepts an ID or @username.
+func (h *Handlers) parseSearchArgs(args string) (string, database.TranscriptSearchFilter, error) {
+	var filter database.TranscriptSearchFilter
+	var terms []string
+
+	for _, field := range strings.Fields(args) {
+		key, value, found := strings.Cut(field, ":")
+		if !found || value == "" {
+			terms = append(terms, field)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "user":
+			if strings.HasPrefix(value, "@") {
+				user, err := h.db.GetUserByUsername(strings.TrimPrefix(value, "@"))
+				if err != nil {
+					return "", filter, errors.New(h.catalog.Admin("users.username_not_found", value))
+				}
+				filter.UserID = user.UserID
+				continue
+			}
+			userID, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return "", filter, errors.New(h.catalog.Admin("search.invalid_user", value))
+			}
+			filter.UserID = userID
+		case "from":
+			since, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return "", filter, errors.New(h.catalog.Admin("search.invalid_date", value))
+			}
+			filter.Since = since
+		case "to":
+			until, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return "", filter, errors.New(h.catalog.Admin("search.invalid_date", value))
+			}
+			filter.Until = until.AddDate(0, 0, 1)
+		case "dir":
+			switch strings.ToLower(value) {
+			case dbmodels.DirectionInbound:
+				filter.Direction = dbmodels.DirectionInbound
+			case dbmodels.DirectionOutbound:
+				filter.Direction = dbmodels.DirectionOutbound
+			default:
+				return "", filter, errors.New(h.catalog.Admin("search.invalid_direction", value))
+			}
+		default:
+			terms = append(terms, field)
+		}
+	}
+
+	return strings.Join(terms, " "), filter, nil
+}
+
+func (h *Handlers) formatSearchResults(query string, results []database.TranscriptSearchResult) string {
+	var text strings.Builder
+	text.WriteString(h.catalog.Admin("search.header", html.EscapeString(query)))
+
+	names := make(map[int64]string)
+	for i, result := range results {
+		name, ok := names[result.UserID]
+		if !ok {
+			name = strconv.FormatInt(result.UserID, 10)
+			if user, err := h.db.GetUser(result.UserID); err == nil {
+				name = user.FirstName
+			}
+			names[result.UserID] = name
+		}
+
+		arrow := "⬅️"
+		if result.Direction == dbmodels.DirectionOutbound {
+			arrow = "➡️"
+		}
+
+		text.WriteString(fmt.Sprintf("\n%d. %s %s (<code>%d</code>) · %s\n",
+			i+1, arrow, html.EscapeString(name), result.UserID, result.SentAt.Format("2006-01-02 15:04")))
+		text.WriteString(formatSnippet(result) + "\n")
+
+		if result.MessageThreadID != 0 {
+			link := services.MessageLink(h.config.AdminGroupID, result.MessageThreadID, result.GroupChatMessageID)
+			text.WriteString(h.catalog.Admin("search.view_message", link))
+		}
+	}
+
+	return text.String()
+}
+
+// formatSnippet renders the highlighted excerpt of a result as HTML.
+func formatSnippet(result database.TranscriptSearchResult) string {
+	snippet := result.Snippet
+	if snippet == "" {
+		snippet = result.Text
+		if utf8.RuneCountInString(snippet) > 80 {
+			snippet = string([]rune(snippet)[:80]) + "…"
+		}
+	}
+
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, "\x02", "<b>")
+	snippet = strings.ReplaceAll(snippet, "\x03", "</b>")
+	return snippet
+}
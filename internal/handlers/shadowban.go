@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"html"
+	"log"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// archiveTopicSetting stores the thread ID of the shadow-ban archive topic
+const archiveTopicSetting = "archive_topic_id"
+
+// handleShadowBanCommand turns a user's shadow ban on or off. Shadow-banned
+// users keep chatting as usual, but nothing reaches their topic.
+func (h *Handlers) handleShadowBanCommand(ctx context.Context, message *models.Message, args string, shadowBanned bool) {
+	command := "/shadowban"
+	if !shadowBanned {
+		command = "/unshadowban"
+	}
+
+	user, _ := h.targetUserOrReply(ctx, message, args, h.catalog.Admin("shadowban.usage", command), false)
+	if user == nil {
+		return
+	}
+
+	if err := h.db.SetShadowBan(user.UserID, shadowBanned); err != nil {
+		log.Printf("Error updating shadow ban for user %d: %v", user.UserID, err)
+		h.sendMessage(ctx, message.Chat.ID, h.catalog.Admin("shadowban.failed"))
+		return
+	}
+
+	h.refreshUserCard(ctx, user.UserID)
+
+	key := "shadowban.done"
+	if !shadowBanned {
+		key = "shadowban.lifted"
+	}
+	h.sendMessage(ctx, message.Chat.ID, h.catalog.Admin(key, user.UserID, user.FirstName))
+}
+
+// archiveMessage accepts a message from a shadow-banned user without any
+// visible difference on their side and copies it, silently, into the archive
+// topic for occasional review
+func (h *Handlers) archiveMessage(ctx context.Context, message *models.Message) {
+	userID := message.From.ID
+
+	if err := h.db.TouchUserActivity(userID, message.From.LanguageCode); err != nil {
+		log.Printf("Error updating user activity: %v", err)
+	}
+
+	if !h.config.HasAdminGroup() {
+		return
+	}
+
+	const maxAttempts = 2
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		threadID, err := h.forumService.EnsureSystemTopic(ctx, archiveTopicSetting, h.catalog.Admin("shadowban.topic_name"))
+		if err != nil {
+			log.Printf("Error creating archive topic: %v", err)
+			return
+		}
+
+		copied, err := h.messageService.ForwardMessageToGroup(ctx, h.bot, message, h.config.AdminGroupID, threadID, true)
+		if err != nil {
+			if services.IsThreadNotFoundError(err) && attempt < maxAttempts-1 {
+				log.Printf("Archive topic %d not found, recreating", threadID)
+				if err := h.forumService.ResetSystemTopic(archiveTopicSetting); err != nil {
+					log.Printf("Error resetting archive topic: %v", err)
+					return
+				}
+				continue
+			}
+			log.Printf("Error archiving message from user %d: %v", userID, err)
+			return
+		}
+
+		_, err = h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+			ChatID:              h.config.AdminGroupID,
+			MessageThreadID:     threadID,
+			Text:                h.catalog.Admin("shadowban.sender", html.EscapeString(message.From.FirstName), userID),
+			ParseMode:           models.ParseModeHTML,
+			DisableNotification: true,
+			ReplyParameters:     &models.ReplyParameters{MessageID: copied.ID},
+		})
+		if err != nil {
+			log.Printf("Error labelling archived message: %v", err)
+		}
+
+		h.recordTranscript(dbmodels.DirectionInbound, userID, message, message.ID, copied.ID, threadID)
+		return
+	}
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"telegram-communication-bot/internal/services"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const whoisResultLimit = 10
+
+var errNoTargetUser = errors.New("no target user")
+
+// resolveTargetUser finds the user an admin command refers to. The first
+// argument may be a numeric user ID or an @username; without one, the user
+// behind the replied-to message or the current topic is used. A number that is
+// not a known user ID is an error, unless numericArgs is set for commands
+// whose own arguments may start with a number: then, inside a topic, it is
+// kept as an argument for the topic's user. Returns the user and the
+// remaining arguments. errNoTargetUser means nothing identified a user, so the
+// caller should show its usage text.
+func (h *Handlers) resolveTargetUser(message *models.Message, args string, numericArgs bool) (*dbmodels.User, string, error) {
+	first, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	rest = strings.TrimSpace(rest)
+	topicUser := h.userFromTopicContext(message)
+
+	if strings.HasPrefix(first, "@") {
+		user, err := h.db.GetUserByUsername(strings.TrimPrefix(first, "@"))
+		if err != nil {
+			return nil, rest, errors.New(h.catalog.Admin("users.username_not_found", first))
+		}
+		return user, rest, nil
+	}
+
+	// A leading number that names no user is part of the arguments only where
+	// the command allows it (e.g. "/tag 2024"); anywhere else a typo in a
+	// user ID must not silently hit the topic's user.
+	if userID, err := strconv.ParseInt(first, 10, 64); err == nil {
+		if user, err := h.db.GetUser(userID); err == nil {
+			return user, rest, nil
+		}
+		if !numericArgs || topicUser == nil {
+			return nil, rest, errors.New(h.catalog.Admin("users.not_found"))
+		}
+	}
+
+	if topicUser != nil {
+		return topicUser, strings.TrimSpace(args), nil
+	}
+
+	return nil, rest, errNoTargetUser
+}
+
+// userFromTopicContext returns the user a message in the admin group is
+// about, based on the replied-to message or the topic it was sent in.
+func (h *Handlers) userFromTopicContext(message *models.Message) *dbmodels.User {
+	if !h.config.HasAdminGroup() || message.Chat.ID != h.config.AdminGroupID {
+		return nil
+	}
+
+	if message.ReplyToMessage != nil {
+		if messageMap, err := h.messageService.GetUserMessageFromGroup(message.ReplyToMessage.ID); err == nil {
+			if user, err := h.db.GetUser(messageMap.UserID); err == nil {
+				return user
+			}
+		}
+	}
+
+	if message.IsTopicMessage && message.MessageThreadID != 0 {
+		if user, err := h.forumService.GetUserByThreadID(message.MessageThreadID); err == nil {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// targetUserOrReply resolves the target user, as resolveTargetUser does, and
+// reports problems to the admin. Returns nil if the command should stop.
+func (h *Handlers) targetUserOrReply(ctx context.Context, message *models.Message, args string, usage string, numericArgs bool) (*dbmodels.User, string) {
+	user, rest, err := h.resolveTargetUser(message, args, numericArgs)
+	if errors.Is(err, errNoTargetUser) {
+		h.sendMessage(ctx, message.Chat.ID, usage)
+		return nil, rest
+	}
+	if err != nil {
+		h.sendMessage(ctx, message.Chat.ID, "❌ "+err.Error())
+		return nil, rest
+	}
+	return user, rest
+}
+
+func (h *Handlers) handleWhoisCommand(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	query := strings.TrimPrefix(strings.TrimSpace(args), "@")
+	if query == "" {
+		if user := h.userFromTopicContext(message); user != nil {
+			query = strconv.FormatInt(user.UserID, 10)
+		} else {
+			h.sendMessage(ctx, chatID, h.catalog.Admin("whois.usage"))
+			return
+		}
+	}
+
+	users, err := h.db.SearchUsers(query, whoisResultLimit)
+	if err != nil {
+		log.Printf("Error searching users: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("whois.failed"))
+		return
+	}
+
+	if len(users) == 0 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("users.no_match"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(h.catalog.Admin("whois.header", len(users)))
+	for i := range users {
+		text.WriteString("\n" + h.formatUserSummary(&users[i]))
+	}
+
+	_, err = h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:             chatID,
+		MessageThreadID:    message.MessageThreadID,
+		Text:               text.String(),
+		ParseMode:          models.ParseModeHTML,
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: tgbot.True()},
+	})
+	if err != nil {
+		log.Printf("Error sending whois results: %v", err)
+	}
+}
+
+// formatUserSummary renders a compact user card with a link to their topic.
+func (h *Handlers) formatUserSummary(user *dbmodels.User) string {
+	var info strings.Builder
+
+	name := user.FirstName
+	if user.LastName != "" {
+		name += " " + user.LastName
+	}
+	info.WriteString(fmt.Sprintf("👤 <b>%s</b>", html.EscapeString(name)))
+	if user.Username != "" {
+		info.WriteString(fmt.Sprintf(" @%s", user.Username))
+	}
+	info.WriteString(fmt.Sprintf(" · <code>%d</code>\n", user.UserID))
+
+	status := h.catalog.Admin("users.status_ok")
+	if h.db.IsUserBanned(user.UserID) {
+		status = h.catalog.Admin("users.status_banned")
+	}
+	info.WriteString(h.catalog.Admin("users.summary_messages", status, user.MessageCount))
+
+	if tags, err := h.db.GetUserTags(user.UserID); err == nil && len(tags) > 0 {
+		info.WriteString(" · 🏷 " + html.EscapeString("#"+strings.Join(tags, " #")))
+	}
+
+	if user.MessageThreadID != 0 && h.config.HasAdminGroup() {
+		link := services.MessageLink(h.config.AdminGroupID, user.MessageThreadID, 0)
+		info.WriteString(h.catalog.Admin("users.open_topic", link))
+	}
+	info.WriteString("\n")
+
+	return info.String()
+}
+
+const userListLimit = 30
+
+// normalizeTag lowercases a tag and strips a leading '#'.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// parseTagFilters extracts tag:<name> tokens from command arguments.
+func parseTagFilters(args string) []string {
+	var tags []string
+	for _, field := range strings.Fields(args) {
+		if value, ok := strings.CutPrefix(field, "tag:"); ok {
+			if tag := normalizeTag(value); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+func (h *Handlers) handleTagCommand(ctx context.Context, message *models.Message, args string, remove bool) {
+	chatID := message.Chat.ID
+
+	command := "/tag"
+	if remove {
+		command = "/untag"
+	}
+	usage := h.catalog.Admin("tag.usage", command)
+
+	user, rest := h.targetUserOrReply(ctx, message, args, usage, true)
+	if user == nil {
+		return
+	}
+
+	var tags []string
+	for _, field := range strings.Fields(rest) {
+		if tag := normalizeTag(field); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		h.sendMessage(ctx, chatID, usage)
+		return
+	}
+
+	for _, tag := range tags {
+		var err error
+		if remove {
+			err = h.db.RemoveUserTag(user.UserID, tag)
+		} else {
+			err = h.db.AddUserTag(user.UserID, tag)
+		}
+		if err != nil {
+			log.Printf("Error updating tag %q for user %d: %v", tag, user.UserID, err)
+			h.sendMessage(ctx, chatID, h.catalog.Admin("tag.failed"))
+			return
+		}
+	}
+
+	h.refreshUserCard(ctx, user.UserID)
+
+	key := "tag.added"
+	if remove {
+		key = "tag.removed"
+	}
+	h.sendMessage(ctx, chatID, h.catalog.Admin(key, user.UserID, user.FirstName, strings.Join(tags, " #")))
+}
+
+func (h *Handlers) handleSetCommand(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+	usage := h.catalog.Admin("set.usage")
+
+	user, rest := h.targetUserOrReply(ctx, message, args, usage, true)
+	if user == nil {
+		return
+	}
+
+	key, value, _ := strings.Cut(rest, " ")
+	key = strings.ToLower(strings.TrimSpace(key))
+	value = strings.TrimSpace(value)
+	if key == "" {
+		h.sendMessage(ctx, chatID, usage)
+		return
+	}
+
+	if value == "" {
+		if err := h.db.DeleteUserAttribute(user.UserID, key); err != nil {
+			log.Printf("Error deleting attribute %q for user %d: %v", key, user.UserID, err)
+			h.sendMessage(ctx, chatID, h.catalog.Admin("set.delete_failed"))
+			return
+		}
+		h.refreshUserCard(ctx, user.UserID)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("set.deleted", user.UserID, key))
+		return
+	}
+
+	if err := h.db.SetUserAttribute(user.UserID, key, value); err != nil {
+		log.Printf("Error setting attribute %q for user %d: %v", key, user.UserID, err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("set.failed"))
+		return
+	}
+
+	h.refreshUserCard(ctx, user.UserID)
+	h.sendMessage(ctx, chatID, h.catalog.Admin("set.done", user.UserID, key, value))
+}
+
+func (h *Handlers) handleUsersCommand(ctx context.Context, message *models.Message, args string) {
+	chatID := message.Chat.ID
+
+	tags := parseTagFilters(args)
+	if len(tags) == 0 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("users.usage"))
+		return
+	}
+
+	users, err := h.db.GetUsersByTags(tags)
+	if err != nil {
+		log.Printf("Error listing users by tags: %v", err)
+		h.sendMessage(ctx, chatID, h.catalog.Admin("common.user_list_failed"))
+		return
+	}
+
+	if len(users) == 0 {
+		h.sendMessage(ctx, chatID, h.catalog.Admin("users.no_match"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(h.catalog.Admin("users.list_header", html.EscapeString(strings.Join(tags, " #")), len(users)))
+	for i := range users {
+		if i == userListLimit {
+			text.WriteString(h.catalog.Admin("users.list_more", len(users)-userListLimit))
+			break
+		}
+		text.WriteString("\n" + h.formatUserSummary(&users[i]))
+	}
+
+	_, err = h.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:             chatID,
+		MessageThreadID:    message.MessageThreadID,
+		Text:               text.String(),
+		ParseMode:          models.ParseModeHTML,
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: tgbot.True()},
+	})
+	if err != nil {
+		log.Printf("Error sending user list: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed locales/*.json
+var builtinLocales embed.FS
+
+// NameKey is the catalog entry holding a locale's own display name
+const NameKey = "locale.name"
+
+// Catalog holds translated messages per locale. Lookups fall back from the
+// exact locale to its base language, then to the default locale, and finally
+// to the key itself.
+type Catalog struct {
+	messages      map[string]map[string]string
+	defaultLocale string
+	adminLocale   string
+}
+
+// NewCatalog loads the built-in locale files and, if dir is set, merges the
+// *.json files found there on top so deployments can override or add locales.
+func NewCatalog(dir, defaultLocale, adminLocale string) (*Catalog, error) {
+	c := &Catalog{messages: make(map[string]map[string]string)}
+
+	entries, err := builtinLocales.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read built-in locales: %w", err)
+	}
+	for _, entry := range entries {
+		data, err := builtinLocales.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read built-in locale %s: %w", entry.Name(), err)
+		}
+		if err := c.merge(entry.Name(), data); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list locale files: %w", err)
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read locale file %s: %w", file, err)
+			}
+			if err := c.merge(filepath.Base(file), data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	c.defaultLocale = normalize(defaultLocale)
+	if !c.Has(c.defaultLocale) {
+		return nil, fmt.Errorf("default locale %q has no locale file", defaultLocale)
+	}
+	c.adminLocale = c.Resolve(adminLocale)
+
+	return c, nil
+}
+
+func (c *Catalog) merge(filename string, data []byte) error {
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("failed to parse locale file %s: %w", filename, err)
+	}
+
+	locale := normalize(strings.TrimSuffix(filename, filepath.Ext(filename)))
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]string)
+	}
+	for key, text := range messages {
+		c.messages[locale][key] = text
+	}
+	return nil
+}
+
+// normalize lowercases a language tag and uses '-' as separator ("pt_BR" -> "pt-br")
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// Has reports whether a locale file was loaded for the exact locale
+func (c *Catalog) Has(locale string) bool {
+	_, ok := c.messages[normalize(locale)]
+	return ok
+}
+
+// Resolve maps a Telegram language code to the closest loaded locale,
+// e.g. "en-US" -> "en". Unknown or empty codes resolve to the default locale.
+func (c *Catalog) Resolve(languageCode string) string {
+	locale := normalize(languageCode)
+	if c.Has(locale) {
+		return locale
+	}
+	if base, _, ok := strings.Cut(locale, "-"); ok && c.Has(base) {
+		return base
+	}
+	return c.defaultLocale
+}
+
+// T returns the message for key in the given locale, formatted with args
+func (c *Catalog) T(locale, key string, args ...interface{}) string {
+	text, ok := c.lookup(c.Resolve(locale), key)
+	if !ok {
+		text, ok = c.lookup(c.defaultLocale, key)
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+func (c *Catalog) lookup(locale, key string) (string, bool) {
+	text, ok := c.messages[locale][key]
+	return text, ok
+}
+
+// Admin returns the message for key in the configured admin locale
+func (c *Catalog) Admin(key string, args ...interface{}) string {
+	return c.T(c.adminLocale, key, args...)
+}
+
+// DefaultLocale returns the fallback locale for users
+func (c *Catalog) DefaultLocale() string {
+	return c.defaultLocale
+}
+
+// AdminLocale returns the locale used for admin-facing messages
+func (c *Catalog) AdminLocale() string {
+	return c.adminLocale
+}
+
+// Locales returns all loaded locales in sorted order
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
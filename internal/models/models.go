@@ -19,6 +19,7 @@ type MediaGroupMessage struct {
 type ForumStatus struct {
 	ID              uint   `gorm:"primarykey" json:"id"`
 	MessageThreadID int    `gorm:"uniqueIndex;not null" json:"message_thread_id"`
+	Name            string `json:"name"`
 	Status          string `gorm:"not null;default:'opened'" json:"status"` // "opened" or "closed"
 	UpdatedAt       time.Time `json:"updated_at"`
 }
@@ -39,8 +40,17 @@ type User struct {
 	LastName        string    `json:"last_name"`
 	Username        string    `json:"username"`
 	IsPremium       bool      `gorm:"default:false" json:"is_premium"`
+	LanguageCode    string    `json:"language_code"` // as reported by the Telegram client
+	Locale          string    `json:"locale"`        // chosen with /language; overrides LanguageCode
 	Verified        bool      `gorm:"default:false" json:"verified"`
+	Trusted         bool      `gorm:"default:false" json:"trusted"` // approved by an admin; exempt from new-user restrictions
 	MessageThreadID int       `json:"message_thread_id"`
+	CardMessageID   int       `json:"card_message_id"` // pinned user card in the topic
+	AssignedAgentID int64     `gorm:"index" json:"assigned_agent_id"`
+	MessageCount    int       `gorm:"default:0" json:"message_count"` // messages relayed to agents
+	LastActiveAt    time.Time `json:"last_active_at"`
+	AfterHoursUntil time.Time `json:"after_hours_until"` // auto-reply already sent for the closed period ending here
+	LastMenuPath    string    `json:"last_menu_path"`    // self-service menu path chosen before reaching an agent
 	UpdatedAt       time.Time `json:"updated_at"`
 	CreatedAt       time.Time `json:"created_at"`
 }
@@ -57,13 +67,220 @@ type UserMessage struct {
 
 // BanStatus represents a user's ban status
 type BanStatus struct {
-	UserID    int64     `gorm:"primarykey" json:"user_id"`
-	IsBanned  bool      `gorm:"default:false" json:"is_banned"`
-	BannedAt  time.Time `json:"banned_at"`
-	Reason    string    `json:"reason"`
+	UserID       int64     `gorm:"primarykey" json:"user_id"`
+	IsBanned     bool      `gorm:"default:false" json:"is_banned"`
+	ShadowBanned bool      `gorm:"default:false" json:"shadow_banned"` // messages go to the archive topic only
+	BannedAt     time.Time `json:"banned_at"`
+	Reason       string    `json:"reason"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// MuteStatus keeps a user's messages from alerting agents until MutedUntil
+type MuteStatus struct {
+	UserID     int64     `gorm:"primarykey" json:"user_id"`
+	MutedUntil time.Time `gorm:"index" json:"muted_until"`
+	Reason     string    `json:"reason"`
+	MutedBy    int64     `json:"muted_by"` // 0 when muted automatically
+	Forward    bool      `json:"forward"`  // forward without notification; otherwise only store
+	Notified   bool      `json:"notified"` // user has been told about the mute
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Agent is an admin who answers conversations
+type Agent struct {
+	AgentID        int64     `gorm:"primarykey" json:"agent_id"`
+	FirstName      string    `json:"first_name"`
+	LastName       string    `json:"last_name"`
+	Username       string    `json:"username"`
+	Online         bool      `gorm:"default:false" json:"online"`
+	LastAssignedAt time.Time `json:"last_assigned_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ConversationSLA tracks whether a user is waiting for an agent reply
+type ConversationSLA struct {
+	UserID         int64     `gorm:"primarykey" json:"user_id"`
+	AwaitingSince  time.Time `gorm:"index" json:"awaiting_since"` // zero when not waiting
+	FirstResponded bool      `gorm:"default:false" json:"first_responded"`
+	Alerted        bool      `gorm:"default:false" json:"alerted"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Response time kinds
+const (
+	ResponseKindFirst = "first"
+	ResponseKindNext  = "next"
+)
+
+// ResponseTime records how long a user waited for an agent reply
+type ResponseTime struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	UserID      int64     `gorm:"not null;index" json:"user_id"`
+	AgentID     int64     `gorm:"index" json:"agent_id"`
+	Kind        string    `gorm:"not null" json:"kind"` // "first" or "next"
+	WaitSeconds int       `gorm:"not null" json:"wait_seconds"`
+	RespondedAt time.Time `gorm:"not null" json:"responded_at"`
+}
+
+// UserTag is a free-form label attached to a user
+type UserTag struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    int64     `gorm:"not null;uniqueIndex:idx_user_tag" json:"user_id"`
+	Tag       string    `gorm:"not null;uniqueIndex:idx_user_tag;index" json:"tag"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserAttribute is a key-value pair of business data attached to a user
+type UserAttribute struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    int64     `gorm:"not null;uniqueIndex:idx_user_attribute" json:"user_id"`
+	Key       string    `gorm:"not null;uniqueIndex:idx_user_attribute" json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Transcript directions
+const (
+	DirectionInbound  = "in"  // user -> admin group
+	DirectionOutbound = "out" // admin group -> user
+)
+
+// TranscriptMessage stores the content of a relayed message so the
+// conversation survives topic deletion
+type TranscriptMessage struct {
+	ID                 uint      `gorm:"primarykey" json:"id"`
+	UserID             int64     `gorm:"not null;index" json:"user_id"`
+	Direction          string    `gorm:"not null" json:"direction"` // "in" or "out"
+	SenderID           int64     `json:"sender_id"`
+	SenderName         string    `json:"sender_name"`
+	Text               string    `json:"text"`     // text or caption
+	Entities           string    `json:"entities"` // JSON-encoded message entities
+	MediaType          string    `json:"media_type"`
+	FileID             string    `json:"file_id"`
+	FileUniqueID       string    `json:"file_unique_id"`
+	UserChatMessageID  int       `json:"user_chat_message_id"`
+	GroupChatMessageID int       `json:"group_chat_message_id"`
+	MessageThreadID    int       `json:"message_thread_id"`
+	SentAt             time.Time `gorm:"not null;index" json:"sent_at"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// AutoReplyRule answers matching user messages with a stored reply
+type AutoReplyRule struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	Pattern         string    `gorm:"not null" json:"pattern"` // comma-separated keywords, or a regex
+	IsRegex         bool      `gorm:"default:false" json:"is_regex"`
+	ReplyText       string    `json:"reply_text"`
+	SourceChatID    int64     `json:"source_chat_id"` // stored message to copy instead of ReplyText
+	SourceMessageID int       `json:"source_message_id"`
+	Forward         bool      `gorm:"default:false" json:"forward"` // still forward the message to the topic
+	HitCount        int       `gorm:"default:0" json:"hit_count"`
+	CreatedBy       int64     `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// AutoReplyVariant is a translated answer of an auto-reply rule, sent to
+// users whose locale matches instead of the rule's default reply
+type AutoReplyVariant struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	RuleID          uint      `gorm:"not null;uniqueIndex:idx_rule_locale" json:"rule_id"`
+	Locale          string    `gorm:"not null;uniqueIndex:idx_rule_locale" json:"locale"`
+	ReplyText       string    `json:"reply_text"`
+	SourceChatID    int64     `json:"source_chat_id"`
+	SourceMessageID int       `json:"source_message_id"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Content filter rule kinds
+const (
+	FilterKindKeyword = "keyword" // comma-separated keywords
+	FilterKindRegex   = "regex"
+	FilterKindDomain  = "domain" // comma-separated domains, subdomains included
+	FilterKindLink    = "link"   // any link at all
+)
+
+// Content filter actions
+const (
+	FilterActionDrop = "drop" // discard silently
+	FilterActionHold = "hold" // keep back for admin review
+	FilterActionWarn = "warn" // discard and warn the user
+	FilterActionBan  = "ban"  // discard and ban the user
+)
+
+// FilterRule screens incoming user messages before they are forwarded
+type FilterRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Kind      string    `gorm:"not null" json:"kind"`
+	Pattern   string    `json:"pattern"` // keywords, regex or domains depending on Kind
+	Action    string    `gorm:"not null" json:"action"`
+	HitCount  int       `gorm:"default:0" json:"hit_count"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// FilterHit logs a message stopped by a filter rule
+type FilterHit struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	RuleID    uint      `gorm:"index" json:"rule_id"`
+	UserID    int64     `gorm:"index" json:"user_id"`
+	Action    string    `json:"action"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// Setting is a bot-wide value the bot maintains itself, such as the ID of a
+// topic it created
+type Setting struct {
+	Key       string    `gorm:"primarykey" json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Why a message was held for review
+const (
+	HeldKindFilter = "filter"
+	HeldKindPolicy = "policy" // new-user restriction; approving also trusts the user
+)
+
+// Held message review states
+const (
+	HeldStatusPending  = "pending"
+	HeldStatusApproved = "approved"
+	HeldStatusRejected = "rejected"
+	HeldStatusBanned   = "banned"
+	HeldStatusFailed   = "failed" // could not be posted for review and was forwarded instead
+)
+
+// HeldMessage is a user message kept back for moderator review
+type HeldMessage struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	UserID          int64     `gorm:"not null;index" json:"user_id"`
+	Kind            string    `json:"kind"`
+	Reason          string    `json:"reason"`
+	Payload         string    `json:"payload"` // JSON-encoded Telegram message
+	Status          string    `gorm:"not null;default:pending;index" json:"status"`
+	ReviewMessageID int       `json:"review_message_id"` // message with the decision buttons
+	ReviewedBy      int64     `json:"reviewed_by"`
+	ReviewedAt      time.Time `json:"reviewed_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// CSATSurvey is the satisfaction rating requested when a conversation is closed
+type CSATSurvey struct {
+	ID               uint      `gorm:"primarykey" json:"id"`
+	UserID           int64     `gorm:"not null;index" json:"user_id"`
+	AgentID          int64     `gorm:"index" json:"agent_id"` // assigned or last replying agent
+	MessageThreadID  int       `json:"message_thread_id"`
+	PromptMessageID  int       `json:"prompt_message_id"`      // rating keyboard in the user chat
+	CommentMessageID int       `json:"comment_message_id"`     // comment prompt the user replies to
+	Score            int       `gorm:"default:0" json:"score"` // 1-5, 0 = not rated yet
+	Comment          string    `json:"comment"`
+	RatedAt          time.Time `gorm:"index" json:"rated_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
 // AutoMigrateAll performs database migration for all models
 func AutoMigrateAll(db *gorm.DB) error {
 	return db.AutoMigrate(
@@ -73,5 +290,19 @@ func AutoMigrateAll(db *gorm.DB) error {
 		&User{},
 		&UserMessage{},
 		&BanStatus{},
+		&MuteStatus{},
+		&TranscriptMessage{},
+		&UserTag{},
+		&UserAttribute{},
+		&Agent{},
+		&ConversationSLA{},
+		&ResponseTime{},
+		&AutoReplyRule{},
+		&AutoReplyVariant{},
+		&CSATSurvey{},
+		&FilterRule{},
+		&FilterHit{},
+		&Setting{},
+		&HeldMessage{},
 	)
 }
\ No newline at end of file
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"telegram-communication-bot/internal/config"
+	"telegram-communication-bot/internal/database"
+	dbmodels "telegram-communication-bot/internal/models"
+
+	"github.com/go-telegram/bot/models"
+)
+
+type AssignmentService struct {
+	config *config.Config
+	db     *database.DB
+	mu     sync.Mutex
+
+	profileMu sync.Mutex
+	profiles  map[int64]dbmodels.Agent // last profile written per agent
+}
+
+func NewAssignmentService(config *config.Config, db *database.DB) *AssignmentService {
+	return &AssignmentService{
+		config:   config,
+		db:       db,
+		profiles: make(map[int64]dbmodels.Agent),
+	}
+}
+
+// RecordAgent keeps the stored profile of an admin up to date. The database is
+// only written when the profile differs from the last one recorded, so calling
+// it for every admin message is cheap. New agents start offline until they
+// use /online.
+func (as *AssignmentService) RecordAgent(from *models.User) error {
+	agent := dbmodels.Agent{
+		AgentID:   from.ID,
+		FirstName: from.FirstName,
+		LastName:  from.LastName,
+		Username:  from.Username,
+	}
+
+	as.profileMu.Lock()
+	defer as.profileMu.Unlock()
+
+	if last, ok := as.profiles[agent.AgentID]; ok && last.FirstName == agent.FirstName &&
+		last.LastName == agent.LastName && last.Username == agent.Username {
+		return nil
+	}
+	if err := as.db.UpsertAgentProfile(&agent); err != nil {
+		return err
+	}
+	as.profiles[agent.AgentID] = agent
+	return nil
+}
+
+// Assign makes an agent responsible for a user's conversation.
+func (as *AssignmentService) Assign(userID int64, agentID int64) error {
+	if err := as.db.SetUserAssignedAgent(userID, agentID); err != nil {
+		return fmt.Errorf("failed to assign user %d: %w", userID, err)
+	}
+	return as.db.TouchAgentAssignment(agentID)
+}
+
+// Unassign clears the responsible agent of a user's conversation.
+func (as *AssignmentService) Unassign(userID int64) error {
+	return as.db.SetUserAssignedAgent(userID, 0)
+}
+
+// AutoAssign picks the online agent who was assigned least recently and
+// assigns the user to them. Returns nil if auto-assignment is disabled or no
+// agent is online.
+func (as *AssignmentService) AutoAssign(userID int64) (*dbmodels.Agent, error) {
+	if !as.config.AutoAssign {
+		return nil, nil
+	}
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	agents, err := as.db.GetOnlineAgents(as.config.AdminUserIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load online agents: %w", err)
+	}
+	if len(agents) == 0 {
+		return nil, nil
+	}
+
+	agent := agents[0]
+	if err := as.Assign(userID, agent.AgentID); err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// SetOnline marks an agent as available (or not) for auto-assignment.
+func (as *AssignmentService) SetOnline(agentID int64, online bool) error {
+	return as.db.SetAgentOnline(agentID, online)
+}
+
+// FindAgent resolves an @username or numeric ID to a known agent.
+func (as *AssignmentService) FindAgent(query string) (*dbmodels.Agent, error) {
+	if username, ok := strings.CutPrefix(query, "@"); ok {
+		return as.db.GetAgentByUsername(username)
+	}
+
+	var agentID int64
+	if _, err := fmt.Sscanf(query, "%d", &agentID); err != nil {
+		return nil, fmt.Errorf("invalid agent: %s", query)
+	}
+	return as.db.GetAgent(agentID)
+}
+
+// AgentDisplayName returns the agent's name for cards and notices.
+func AgentDisplayName(agent *dbmodels.Agent) string {
+	name := strings.TrimSpace(agent.FirstName + " " + agent.LastName)
+	if agent.Username != "" {
+		name += " (@" + agent.Username + ")"
+	}
+	return name
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"telegram-communication-bot/internal/database"
+	dbmodels "telegram-communication-bot/internal/models"
+)
+
+// compiledRule pairs a stored rule with its prepared matcher
+type compiledRule struct {
+	rule     dbmodels.AutoReplyRule
+	keywords []string
+	regex    *regexp.Regexp
+}
+
+func (cr *compiledRule) matches(text string) bool {
+	if cr.regex != nil {
+		return cr.regex.MatchString(text)
+	}
+	lower := strings.ToLower(text)
+	for _, keyword := range cr.keywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// AutoReplyService matches user messages against admin-defined FAQ rules.
+// Rules are cached in memory and reloaded whenever they change.
+type AutoReplyService struct {
+	db       *database.DB
+	mu       sync.RWMutex
+	rules    []compiledRule
+	variants map[uint]map[string]dbmodels.AutoReplyVariant // rule ID -> locale -> variant
+}
+
+func NewAutoReplyService(db *database.DB) *AutoReplyService {
+	ars := &AutoReplyService{db: db}
+	if err := ars.Reload(); err != nil {
+		log.Printf("Error loading auto-reply rules: %v", err)
+	}
+	return ars
+}
+
+// Reload refreshes the rule cache from the database
+func (ars *AutoReplyService) Reload() error {
+	rules, err := ars.db.GetAutoReplyRules()
+	if err != nil {
+		return err
+	}
+
+	stored, err := ars.db.GetAutoReplyVariants()
+	if err != nil {
+		return err
+	}
+	variants := make(map[uint]map[string]dbmodels.AutoReplyVariant)
+	for _, variant := range stored {
+		if variants[variant.RuleID] == nil {
+			variants[variant.RuleID] = make(map[string]dbmodels.AutoReplyVariant)
+		}
+		variants[variant.RuleID][variant.Locale] = variant
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		cr, err := compileRule(rule)
+		if err != nil {
+			log.Printf("Skipping auto-reply rule %d: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, cr)
+	}
+
+	ars.mu.Lock()
+	ars.rules = compiled
+	ars.variants = variants
+	ars.mu.Unlock()
+	return nil
+}
+
+func compileRule(rule dbmodels.AutoReplyRule) (compiledRule, error) {
+	cr := compiledRule{rule: rule}
+	if rule.IsRegex {
+		re, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return cr, err
+		}
+		cr.regex = re
+		return cr, nil
+	}
+
+	for _, keyword := range strings.Split(rule.Pattern, ",") {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			cr.keywords = append(cr.keywords, keyword)
+		}
+	}
+	if len(cr.keywords) == 0 {
+		return cr, fmt.Errorf("no keywords")
+	}
+	return cr, nil
+}
+
+// ParsePattern interprets "/regex/" as a regular expression and anything
+// else as comma-separated keywords
+func ParsePattern(spec string) (pattern string, isRegex bool) {
+	spec = strings.TrimSpace(spec)
+	if len(spec) > 2 && strings.HasPrefix(spec, "/") && strings.HasSuffix(spec, "/") {
+		return spec[1 : len(spec)-1], true
+	}
+	return spec, false
+}
+
+// AddRule validates, stores and activates a new rule
+func (ars *AutoReplyService) AddRule(rule *dbmodels.AutoReplyRule) error {
+	if _, err := compileRule(*rule); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	if err := ars.db.CreateAutoReplyRule(rule); err != nil {
+		return err
+	}
+	return ars.Reload()
+}
+
+// DeleteRule removes a rule and deactivates it
+func (ars *AutoReplyService) DeleteRule(id uint) error {
+	if err := ars.db.DeleteAutoReplyRule(id); err != nil {
+		return err
+	}
+	return ars.Reload()
+}
+
+// SetVariant stores the reply of a rule for one locale
+func (ars *AutoReplyService) SetVariant(variant *dbmodels.AutoReplyVariant) error {
+	if _, err := ars.db.GetAutoReplyRule(variant.RuleID); err != nil {
+		return err
+	}
+	if err := ars.db.SetAutoReplyVariant(variant); err != nil {
+		return err
+	}
+	return ars.Reload()
+}
+
+// VariantLocales returns the locales a rule has translated replies for
+func (ars *AutoReplyService) VariantLocales(ruleID uint) []string {
+	ars.mu.RLock()
+	defer ars.mu.RUnlock()
+
+	locales := make([]string, 0, len(ars.variants[ruleID]))
+	for locale := range ars.variants[ruleID] {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Localize returns the rule with its reply replaced by the variant for
+// locale, or the rule unchanged if there is no such variant
+func (ars *AutoReplyService) Localize(rule *dbmodels.AutoReplyRule, locale string) *dbmodels.AutoReplyRule {
+	ars.mu.RLock()
+	variant, ok := ars.variants[rule.ID][locale]
+	ars.mu.RUnlock()
+	if !ok {
+		return rule
+	}
+
+	localized := *rule
+	localized.ReplyText = variant.ReplyText
+	localized.SourceChatID = variant.SourceChatID
+	localized.SourceMessageID = variant.SourceMessageID
+	return &localized
+}
+
+// ListRules returns all rules with up-to-date hit counters
+func (ars *AutoReplyService) ListRules() ([]dbmodels.AutoReplyRule, error) {
+	return ars.db.GetAutoReplyRules()
+}
+
+// Match returns the first rule matching the text and counts the hit.
+// Returns nil if no rule matches.
+func (ars *AutoReplyService) Match(text string) *dbmodels.AutoReplyRule {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	ars.mu.RLock()
+	defer ars.mu.RUnlock()
+
+	for i := range ars.rules {
+		if !ars.rules[i].matches(text) {
+			continue
+		}
+		rule := ars.rules[i].rule
+		if err := ars.db.IncrementAutoReplyHits(rule.ID); err != nil {
+			log.Printf("Error counting hit for auto-reply rule %d: %v", rule.ID, err)
+		}
+		return &rule
+	}
+	return nil
+}
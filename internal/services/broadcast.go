@@ -0,0 +1,99 @@
+package services
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// BroadcastDraft is a broadcast being assembled from one source message per locale
+type BroadcastDraft struct {
+	Variants map[string]*models.Message // locale -> message to copy
+}
+
+// Locales returns the locales the draft has a message for, sorted
+func (d *BroadcastDraft) Locales() []string {
+	locales := make([]string, 0, len(d.Variants))
+	for locale := range d.Variants {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Pick returns the message for locale, falling back to the one for
+// fallbackLocale. The second value is the locale of the message returned.
+func (d *BroadcastDraft) Pick(locale, fallbackLocale string) (*models.Message, string) {
+	if msg, ok := d.Variants[locale]; ok {
+		return msg, locale
+	}
+	return d.Variants[fallbackLocale], fallbackLocale
+}
+
+// BroadcastDrafts keeps each admin's unsent multi-language broadcast in memory
+type BroadcastDrafts struct {
+	mu     sync.Mutex
+	drafts map[int64]*BroadcastDraft // admin user ID -> draft
+}
+
+func NewBroadcastDrafts() *BroadcastDrafts {
+	return &BroadcastDrafts{
+		drafts: make(map[int64]*BroadcastDraft),
+	}
+}
+
+// AddVariant sets the message for one locale of the admin's draft, starting
+// a new draft if needed. Returns the locales the draft now covers.
+func (bd *BroadcastDrafts) AddVariant(adminID int64, locale string, msg *models.Message) []string {
+	bd.mu.Lock()
+	defer bd.mu.Unlock()
+
+	draft, ok := bd.drafts[adminID]
+	if !ok {
+		draft = &BroadcastDraft{Variants: make(map[string]*models.Message)}
+		bd.drafts[adminID] = draft
+	}
+	draft.Variants[locale] = msg
+	return draft.Locales()
+}
+
+// Take removes the admin's draft and returns a copy of it, which the caller
+// owns and may hand to another goroutine
+func (bd *BroadcastDrafts) Take(adminID int64) (*BroadcastDraft, bool) {
+	bd.mu.Lock()
+	defer bd.mu.Unlock()
+
+	draft, ok := bd.drafts[adminID]
+	if !ok {
+		return nil, false
+	}
+	delete(bd.drafts, adminID)
+
+	taken := &BroadcastDraft{Variants: make(map[string]*models.Message, len(draft.Variants))}
+	for locale, msg := range draft.Variants {
+		taken.Variants[locale] = msg
+	}
+	return taken, true
+}
+
+// Restore puts back a draft obtained from Take that was not sent, unless the
+// admin has started a new draft since
+func (bd *BroadcastDrafts) Restore(adminID int64, draft *BroadcastDraft) {
+	bd.mu.Lock()
+	defer bd.mu.Unlock()
+
+	if _, ok := bd.drafts[adminID]; !ok {
+		bd.drafts[adminID] = draft
+	}
+}
+
+// Discard drops the admin's draft. Returns false if there was none.
+func (bd *BroadcastDrafts) Discard(adminID int64) bool {
+	bd.mu.Lock()
+	defer bd.mu.Unlock()
+
+	_, ok := bd.drafts[adminID]
+	delete(bd.drafts, adminID)
+	return ok
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"telegram-communication-bot/internal/config"
+	"telegram-communication-bot/internal/database"
+	"telegram-communication-bot/internal/i18n"
+	"time"
+)
+
+// maxScheduleLookahead bounds the search for the next opening time
+const maxScheduleLookahead = 400
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// openRange is a daily opening interval in minutes since midnight
+type openRange struct {
+	start int
+	end   int
+}
+
+// BusinessHours answers whether the support team is currently working and
+// sends at most one out-of-office notice per closed period to each user.
+type BusinessHours struct {
+	db       *database.DB
+	location *time.Location
+	schedule [7][]openRange
+	holidays map[string]bool
+	message  string
+	enabled  bool
+	catalog  *i18n.Catalog
+	mu       sync.Mutex
+}
+
+// NewBusinessHours parses the weekly schedule and holiday list from config
+func NewBusinessHours(cfg *config.Config, db *database.DB, catalog *i18n.Catalog) (*BusinessHours, error) {
+	bh := &BusinessHours{
+		db:       db,
+		catalog:  catalog,
+		location: cfg.BusinessTimezone,
+		holidays: make(map[string]bool),
+		message:  cfg.OutOfHoursMessage,
+	}
+	if bh.location == nil {
+		bh.location = time.Local
+	}
+
+	if cfg.BusinessHours == "" {
+		return bh, nil
+	}
+
+	for _, entry := range strings.Split(cfg.BusinessHours, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if err := bh.parseScheduleEntry(entry); err != nil {
+			return nil, fmt.Errorf("invalid BUSINESS_HOURS entry %q: %w", entry, err)
+		}
+	}
+
+	for _, holiday := range cfg.BusinessHolidays {
+		if err := bh.parseHoliday(holiday); err != nil {
+			return nil, fmt.Errorf("invalid BUSINESS_HOLIDAYS entry %q: %w", holiday, err)
+		}
+	}
+
+	for day := range bh.schedule {
+		sort.Slice(bh.schedule[day], func(i, j int) bool {
+			return bh.schedule[day][i].start < bh.schedule[day][j].start
+		})
+		if len(bh.schedule[day]) > 0 {
+			bh.enabled = true
+		}
+	}
+	if !bh.enabled {
+		return nil, fmt.Errorf("BUSINESS_HOURS does not open on any day")
+	}
+
+	return bh, nil
+}
+
+// parseScheduleEntry parses "mon-fri 09:00-12:00,13:00-18:00"
+func (bh *BusinessHours) parseScheduleEntry(entry string) error {
+	fields := strings.Fields(entry)
+	if len(fields) != 2 {
+		return fmt.Errorf("expected \"<days> <HH:MM-HH:MM>[,...]\"")
+	}
+
+	days, err := parseWeekdays(fields[0])
+	if err != nil {
+		return err
+	}
+
+	var ranges []openRange
+	for _, part := range strings.Split(fields[1], ",") {
+		bounds := strings.Split(part, "-")
+		if len(bounds) != 2 {
+			return fmt.Errorf("invalid time range %q", part)
+		}
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return err
+		}
+		if end <= start {
+			return fmt.Errorf("time range %q must end after it starts", part)
+		}
+		ranges = append(ranges, openRange{start: start, end: end})
+	}
+
+	for _, day := range days {
+		bh.schedule[day] = append(bh.schedule[day], ranges...)
+	}
+	return nil
+}
+
+// parseWeekdays parses "mon", "mon-fri" or "sat,sun"
+func parseWeekdays(spec string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(strings.ToLower(spec), ",") {
+		bounds := strings.Split(part, "-")
+		first, ok := weekdayNames[bounds[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", bounds[0])
+		}
+		if len(bounds) == 1 {
+			days = append(days, first)
+			continue
+		}
+		last, ok := weekdayNames[bounds[1]]
+		if !ok || len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid weekday range %q", part)
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseClock converts "HH:MM" to minutes since midnight; "24:00" is allowed as an end time
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// parseHoliday accepts a single date or an inclusive "from..to" date range
+func (bh *BusinessHours) parseHoliday(spec string) error {
+	bounds := strings.SplitN(spec, "..", 2)
+	from, err := time.ParseInLocation("2006-01-02", bounds[0], bh.location)
+	if err != nil {
+		return err
+	}
+	to := from
+	if len(bounds) == 2 {
+		if to, err = time.ParseInLocation("2006-01-02", bounds[1], bh.location); err != nil {
+			return err
+		}
+	}
+	if to.Before(from) {
+		return fmt.Errorf("holiday range ends before it starts")
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		bh.holidays[day.Format("2006-01-02")] = true
+	}
+	return nil
+}
+
+// IsEnabled returns true if a business hours schedule is configured
+func (bh *BusinessHours) IsEnabled() bool {
+	return bh.enabled
+}
+
+// IsOpen reports whether the given moment falls within business hours
+func (bh *BusinessHours) IsOpen(t time.Time) bool {
+	if !bh.enabled {
+		return true
+	}
+	local := t.In(bh.location)
+	if bh.holidays[local.Format("2006-01-02")] {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+	for _, r := range bh.schedule[local.Weekday()] {
+		if minute >= r.start && minute < r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpening returns the start of the next business-hours interval after t.
+// Returns the zero time if none is found within the lookahead window.
+func (bh *BusinessHours) NextOpening(t time.Time) time.Time {
+	if !bh.enabled {
+		return time.Time{}
+	}
+	local := t.In(bh.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, bh.location)
+
+	for offset := 0; offset < maxScheduleLookahead; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		if bh.holidays[day.Format("2006-01-02")] {
+			continue
+		}
+		for _, r := range bh.schedule[day.Weekday()] {
+			start := time.Date(day.Year(), day.Month(), day.Day(), r.start/60, r.start%60, 0, 0, bh.location)
+			if start.After(t) {
+				return start
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// ClaimOutOfHoursNotice returns the next opening time and true if the user has
+// not yet been sent an out-of-office notice for the current closed period.
+// The claim is persisted so restarts don't repeat the notice.
+func (bh *BusinessHours) ClaimOutOfHoursNotice(userID int64, now time.Time) (time.Time, bool) {
+	if bh.IsOpen(now) {
+		return time.Time{}, false
+	}
+	nextOpen := bh.NextOpening(now)
+
+	bh.mu.Lock()
+	defer bh.mu.Unlock()
+
+	user, err := bh.db.GetUser(userID)
+	if err != nil {
+		return nextOpen, false
+	}
+	// The period is identified by its next opening time
+	if !user.AfterHoursUntil.IsZero() && now.Before(user.AfterHoursUntil) {
+		return nextOpen, false
+	}
+
+	until := nextOpen
+	if until.IsZero() {
+		until = now.AddDate(0, 0, maxScheduleLookahead)
+	}
+	if err := bh.db.SetUserAfterHoursUntil(userID, until); err != nil {
+		return nextOpen, false
+	}
+	return nextOpen, true
+}
+
+// FormatOpening renders an opening time in the given locale
+func (bh *BusinessHours) FormatOpening(locale string, t time.Time) string {
+	if t.IsZero() {
+		return bh.catalog.T(locale, "hours.tbd")
+	}
+	local := t.In(bh.location)
+	weekday := bh.catalog.T(locale, fmt.Sprintf("weekday.%d", local.Weekday()))
+	return fmt.Sprintf("%s %s", weekday, local.Format("01-02 15:04"))
+}
+
+// FormatAutoReply returns the out-of-office reply in the given locale. A
+// configured message overrides the catalog; its {next_open} placeholder is
+// replaced by the next opening time.
+func (bh *BusinessHours) FormatAutoReply(locale string, nextOpen time.Time) string {
+	opening := bh.FormatOpening(locale, nextOpen)
+	if bh.message == "" {
+		return bh.catalog.T(locale, "hours.auto_reply", opening)
+	}
+	return strings.ReplaceAll(bh.message, "{next_open}", opening)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sync"
+	"telegram-communication-bot/internal/i18n"
 	"time"
 
 	"github.com/go-telegram/bot/models"
@@ -22,10 +23,12 @@ type CaptchaService struct {
 	cooldowns        map[int64]time.Time
 	expiration       time.Duration
 	cooldownDuration time.Duration
+	catalog          *i18n.Catalog
 }
 
-func NewCaptchaService() *CaptchaService {
+func NewCaptchaService(catalog *i18n.Catalog) *CaptchaService {
 	return &CaptchaService{
+		catalog:          catalog,
 		challenges:       make(map[int64]*CaptchaChallenge),
 		cooldowns:        make(map[int64]time.Time),
 		expiration:       5 * time.Minute,
@@ -33,13 +36,13 @@ func NewCaptchaService() *CaptchaService {
 	}
 }
 
-// GenerateChallenge creates a math CAPTCHA and returns the question text with an inline keyboard.
-// If a non-expired challenge already exists for the user, it is replaced.
-func (s *CaptchaService) GenerateChallenge(userID int64) (string, models.InlineKeyboardMarkup) {
+// GenerateChallenge creates a math CAPTCHA and returns the question text in the given locale
+// with an inline keyboard. If a non-expired challenge already exists for the user, it is replaced.
+func (s *CaptchaService) GenerateChallenge(userID int64, locale string) (string, models.InlineKeyboardMarkup) {
 	a := rand.Intn(20) + 1
 	b := rand.Intn(20) + 1
 	answer := a + b
-	question := fmt.Sprintf("🔒 请完成人机验证\n\n❓ %d + %d = ?", a, b)
+	question := s.catalog.T(locale, "captcha.question", a, b)
 
 	options := generateOptions(answer)
 
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// topicReply is the most recent agent reply relayed from a topic.
+type topicReply struct {
+	AgentID   int64
+	AgentName string
+	At        time.Time
+}
+
+// HeldReply is an admin reply waiting for soft-lock confirmation.
+type HeldReply struct {
+	Message *models.Message
+	UserID  int64
+	AgentID int64
+	HeldAt  time.Time
+}
+
+// CollisionDetector notices when two agents reply in the same topic within
+// a short window, and holds replies for confirmation in soft-lock mode.
+type CollisionDetector struct {
+	window   time.Duration
+	softLock bool
+	mu       sync.Mutex
+	replies  map[int]topicReply // message thread ID -> last reply
+	held     map[int]*HeldReply // group message ID -> held reply
+}
+
+func NewCollisionDetector(windowSeconds int, softLock bool) *CollisionDetector {
+	return &CollisionDetector{
+		window:   time.Duration(windowSeconds) * time.Second,
+		softLock: softLock,
+		replies:  make(map[int]topicReply),
+		held:     make(map[int]*HeldReply),
+	}
+}
+
+// IsEnabled returns true if collision detection is enabled
+func (cd *CollisionDetector) IsEnabled() bool {
+	return cd.window > 0
+}
+
+// IsSoftLock reports whether colliding replies must be confirmed before relaying.
+func (cd *CollisionDetector) IsSoftLock() bool {
+	return cd.softLock
+}
+
+// Check returns the name of another agent who replied in the topic within
+// the window, if any.
+func (cd *CollisionDetector) Check(threadID int, agentID int64) (string, bool) {
+	if !cd.IsEnabled() || threadID == 0 {
+		return "", false
+	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	last, ok := cd.replies[threadID]
+	if !ok || last.AgentID == agentID || time.Since(last.At) > cd.window {
+		return "", false
+	}
+	return last.AgentName, true
+}
+
+// Record notes that an agent's reply was relayed from the topic.
+func (cd *CollisionDetector) Record(threadID int, agentID int64, agentName string) {
+	if !cd.IsEnabled() || threadID == 0 {
+		return
+	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	cd.replies[threadID] = topicReply{AgentID: agentID, AgentName: agentName, At: time.Now()}
+}
+
+// Hold keeps a reply until the agent confirms or cancels it.
+func (cd *CollisionDetector) Hold(message *models.Message, userID int64) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	cd.held[message.ID] = &HeldReply{
+		Message: message,
+		UserID:  userID,
+		AgentID: message.From.ID,
+		HeldAt:  time.Now(),
+	}
+}
+
+// Release removes and returns a held reply.
+func (cd *CollisionDetector) Release(messageID int) (*HeldReply, bool) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	held, ok := cd.held[messageID]
+	if ok {
+		delete(cd.held, messageID)
+	}
+	return held, ok
+}
+
+// CleanupStaleEntries drops reply records older than the window and held
+// replies nobody confirmed within an hour.
+func (cd *CollisionDetector) CleanupStaleEntries() {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	for threadID, last := range cd.replies {
+		if time.Since(last.At) > cd.window {
+			delete(cd.replies, threadID)
+		}
+	}
+	for messageID, held := range cd.held {
+		if time.Since(held.HeldAt) > time.Hour {
+			delete(cd.held, messageID)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"telegram-communication-bot/internal/config"
+	"telegram-communication-bot/internal/database"
+	dbmodels "telegram-communication-bot/internal/models"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// CSATCallbackPrefix starts rating callback data: csat_<surveyID>_<score>
+const CSATCallbackPrefix = "csat_"
+
+// CSATMaxScore is the highest rating a user can give
+const CSATMaxScore = 5
+
+var (
+	ErrSurveyNotFound     = errors.New("survey not found")
+	ErrSurveyAlreadyRated = errors.New("survey already rated")
+)
+
+// CSATService asks users to rate closed conversations and stores the results
+type CSATService struct {
+	config *config.Config
+	db     *database.DB
+}
+
+func NewCSATService(config *config.Config, db *database.DB) *CSATService {
+	return &CSATService{
+		config: config,
+		db:     db,
+	}
+}
+
+// IsEnabled returns true if satisfaction surveys are sent on close
+func (cs *CSATService) IsEnabled() bool {
+	return cs.config.CSATEnabled
+}
+
+// StartSurvey creates a pending survey for the conversation being closed,
+// crediting the assigned agent or, failing that, the last agent who replied.
+func (cs *CSATService) StartSurvey(user *dbmodels.User) (*dbmodels.CSATSurvey, error) {
+	agentID := user.AssignedAgentID
+	if agentID == 0 {
+		agentID, _ = cs.db.GetLastOutboundSender(user.UserID)
+	}
+
+	survey := &dbmodels.CSATSurvey{
+		UserID:          user.UserID,
+		AgentID:         agentID,
+		MessageThreadID: user.MessageThreadID,
+	}
+	if err := cs.db.SaveCSATSurvey(survey); err != nil {
+		return nil, fmt.Errorf("failed to create survey: %w", err)
+	}
+	return survey, nil
+}
+
+// AttachPrompt remembers the rating message sent to the user
+func (cs *CSATService) AttachPrompt(survey *dbmodels.CSATSurvey, messageID int) error {
+	survey.PromptMessageID = messageID
+	return cs.db.SaveCSATSurvey(survey)
+}
+
+// AttachCommentPrompt remembers the message the user can reply to with a comment
+func (cs *CSATService) AttachCommentPrompt(survey *dbmodels.CSATSurvey, messageID int) error {
+	survey.CommentMessageID = messageID
+	return cs.db.SaveCSATSurvey(survey)
+}
+
+// Rate stores the user's score; each survey can only be rated once
+func (cs *CSATService) Rate(surveyID uint, userID int64, score int) (*dbmodels.CSATSurvey, error) {
+	if score < 1 || score > CSATMaxScore {
+		return nil, fmt.Errorf("invalid score: %d", score)
+	}
+
+	survey, err := cs.db.GetCSATSurvey(surveyID)
+	if err != nil || survey.UserID != userID {
+		return nil, ErrSurveyNotFound
+	}
+	if survey.Score != 0 {
+		return survey, ErrSurveyAlreadyRated
+	}
+
+	survey.Score = score
+	survey.RatedAt = time.Now()
+	if err := cs.db.SaveCSATSurvey(survey); err != nil {
+		return nil, err
+	}
+	return survey, nil
+}
+
+// AddComment attaches a comment if the user replied to a survey's comment prompt.
+// Returns ErrSurveyNotFound if the reply isn't for a survey.
+func (cs *CSATService) AddComment(userID int64, replyToMessageID int, comment string) (*dbmodels.CSATSurvey, error) {
+	survey, err := cs.db.GetCSATSurveyByCommentMessage(userID, replyToMessageID)
+	if err != nil {
+		return nil, ErrSurveyNotFound
+	}
+
+	survey.Comment = comment
+	if err := cs.db.SaveCSATSurvey(survey); err != nil {
+		return nil, err
+	}
+	return survey, nil
+}
+
+// Keyboard returns the 1-5 rating buttons for a survey
+func (cs *CSATService) Keyboard(surveyID uint) *models.InlineKeyboardMarkup {
+	row := make([]models.InlineKeyboardButton, 0, CSATMaxScore)
+	for score := 1; score <= CSATMaxScore; score++ {
+		row = append(row, models.InlineKeyboardButton{
+			Text:         fmt.Sprintf("%d⭐", score),
+			CallbackData: fmt.Sprintf("%s%d_%d", CSATCallbackPrefix, surveyID, score),
+		})
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{row}}
+}
+
+// FormatStars renders a score as stars, e.g. "⭐⭐⭐⭐ (4/5)"
+func FormatStars(score int) string {
+	return fmt.Sprintf("%s (%d/%d)", strings.Repeat("⭐", score), score, CSATMaxScore)
+}
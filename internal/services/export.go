@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	dbmodels "telegram-communication-bot/internal/models"
+	"time"
+)
+
+// Supported transcript export formats
+const (
+	ExportFormatHTML     = "html"
+	ExportFormatMarkdown = "md"
+)
+
+const exportTimeLayout = "2006-01-02 15:04:05"
+
+// ExportTranscript renders a user's stored conversation as a self-contained
+// HTML or Markdown document. Returns the suggested file name and content.
+func (ts *TranscriptService) ExportTranscript(user *dbmodels.User, format string) (string, []byte, error) {
+	messages, err := ts.GetTranscript(user.UserID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load transcript: %w", err)
+	}
+
+	filename := fmt.Sprintf("transcript_%d_%s.%s", user.UserID, time.Now().Format("20060102_150405"), format)
+
+	switch format {
+	case ExportFormatHTML:
+		return filename, ts.renderTranscriptHTML(user, messages), nil
+	case ExportFormatMarkdown:
+		return filename, ts.renderTranscriptMarkdown(user, messages), nil
+	default:
+		return "", nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func (ts *TranscriptService) renderTranscriptHTML(user *dbmodels.User, messages []dbmodels.TranscriptMessage) []byte {
+	var out strings.Builder
+	title := ts.catalog.Admin("export.title", userDisplayName(user), user.UserID)
+
+	out.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	out.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	out.WriteString(`<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; max-width: 800px; margin: 2em auto; color: #222; }
+.msg { margin: .6em 0; padding: .6em .9em; border-radius: 8px; max-width: 75%; }
+.in { background: #f1f1f1; }
+.out { background: #dcf3ff; margin-left: auto; }
+.meta { font-size: .8em; color: #666; margin-bottom: .3em; }
+.media { font-style: italic; color: #555; }
+.text { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+`)
+	out.WriteString("<h1>" + html.EscapeString(title) + "</h1>\n")
+	out.WriteString("<p>" + html.EscapeString(ts.catalog.Admin("export.summary", time.Now().Format(exportTimeLayout), len(messages))) + "</p>\n")
+
+	for _, msg := range messages {
+		out.WriteString(fmt.Sprintf("<div class=\"msg %s\">\n", msg.Direction))
+		out.WriteString(fmt.Sprintf("<div class=\"meta\">%s · %s</div>\n",
+			msg.SentAt.Format(exportTimeLayout), html.EscapeString(ts.transcriptSender(user, &msg))))
+		if msg.MediaType != "" {
+			out.WriteString(fmt.Sprintf("<div class=\"media\">%s</div>\n", html.EscapeString(mediaPlaceholder(&msg))))
+		}
+		if msg.Text != "" {
+			out.WriteString("<div class=\"text\">" + html.EscapeString(msg.Text) + "</div>\n")
+		}
+		out.WriteString("</div>\n")
+	}
+
+	out.WriteString("</body>\n</html>\n")
+	return []byte(out.String())
+}
+
+func (ts *TranscriptService) renderTranscriptMarkdown(user *dbmodels.User, messages []dbmodels.TranscriptMessage) []byte {
+	var out strings.Builder
+
+	out.WriteString("# " + ts.catalog.Admin("export.title", userDisplayName(user), user.UserID) + "\n\n")
+	out.WriteString(ts.catalog.Admin("export.summary", time.Now().Format(exportTimeLayout), len(messages)) + "\n\n")
+
+	for _, msg := range messages {
+		arrow := "⬅️"
+		if msg.Direction == dbmodels.DirectionOutbound {
+			arrow = "➡️"
+		}
+		out.WriteString(fmt.Sprintf("**%s %s** · %s\n\n", arrow, ts.transcriptSender(user, &msg), msg.SentAt.Format(exportTimeLayout)))
+		if msg.MediaType != "" {
+			out.WriteString("_" + mediaPlaceholder(&msg) + "_\n\n")
+		}
+		if msg.Text != "" {
+			for _, line := range strings.Split(msg.Text, "\n") {
+				out.WriteString("> " + line + "\n")
+			}
+			out.WriteString("\n")
+		}
+	}
+
+	return []byte(out.String())
+}
+
+// transcriptSender names who sent a message: the user for inbound messages,
+// the replying agent for outbound ones.
+func (ts *TranscriptService) transcriptSender(user *dbmodels.User, msg *dbmodels.TranscriptMessage) string {
+	if msg.Direction == dbmodels.DirectionInbound {
+		return userDisplayName(user)
+	}
+	if msg.SenderName != "" {
+		return ts.catalog.Admin("export.agent") + " " + msg.SenderName
+	}
+	return ts.catalog.Admin("export.agent")
+}
+
+func mediaPlaceholder(msg *dbmodels.TranscriptMessage) string {
+	if msg.FileID != "" {
+		return fmt.Sprintf("[%s: %s]", msg.MediaType, msg.FileID)
+	}
+	return fmt.Sprintf("[%s]", msg.MediaType)
+}
+
+func userDisplayName(user *dbmodels.User) string {
+	name := user.FirstName
+	if user.LastName != "" {
+		name += " " + user.LastName
+	}
+	if user.Username != "" {
+		name += " @" + user.Username
+	}
+	return name
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"telegram-communication-bot/internal/database"
+	dbmodels "telegram-communication-bot/internal/models"
+	"unicode/utf16"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// filterHitTextLength caps how much of a filtered message is kept in the hit log
+const filterHitTextLength = 200
+
+// compiledFilter pairs a stored filter rule with its prepared matcher
+type compiledFilter struct {
+	rule     dbmodels.FilterRule
+	keywords []string
+	regex    *regexp.Regexp
+	domains  []string
+}
+
+func (cf *compiledFilter) matches(text string, hosts []string) bool {
+	switch cf.rule.Kind {
+	case dbmodels.FilterKindRegex:
+		return cf.regex.MatchString(text)
+	case dbmodels.FilterKindLink:
+		return len(hosts) > 0
+	case dbmodels.FilterKindDomain:
+		for _, host := range hosts {
+			for _, domain := range cf.domains {
+				if host == domain || strings.HasSuffix(host, "."+domain) {
+					return true
+				}
+			}
+		}
+		return false
+	default:
+		lower := strings.ToLower(text)
+		for _, keyword := range cf.keywords {
+			if strings.Contains(lower, keyword) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ContentFilter screens user messages against admin-defined keyword, regex,
+// link and domain rules. Rules are cached in memory and reloaded on change.
+type ContentFilter struct {
+	db    *database.DB
+	mu    sync.RWMutex
+	rules []compiledFilter
+}
+
+func NewContentFilter(db *database.DB) *ContentFilter {
+	cf := &ContentFilter{db: db}
+	if err := cf.Reload(); err != nil {
+		log.Printf("Error loading filter rules: %v", err)
+	}
+	return cf
+}
+
+// Reload refreshes the rule cache from the database
+func (cf *ContentFilter) Reload() error {
+	rules, err := cf.db.GetFilterRules()
+	if err != nil {
+		return err
+	}
+
+	compiled := make([]compiledFilter, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileFilter(rule)
+		if err != nil {
+			log.Printf("Skipping filter rule %d: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	cf.mu.Lock()
+	cf.rules = compiled
+	cf.mu.Unlock()
+	return nil
+}
+
+func compileFilter(rule dbmodels.FilterRule) (compiledFilter, error) {
+	c := compiledFilter{rule: rule}
+
+	switch rule.Action {
+	case dbmodels.FilterActionDrop, dbmodels.FilterActionHold, dbmodels.FilterActionWarn, dbmodels.FilterActionBan:
+	default:
+		return c, fmt.Errorf("unknown action %q", rule.Action)
+	}
+
+	switch rule.Kind {
+	case dbmodels.FilterKindRegex:
+		re, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return c, err
+		}
+		c.regex = re
+		return c, nil
+	case dbmodels.FilterKindLink:
+		return c, nil
+	case dbmodels.FilterKindDomain:
+		for _, domain := range strings.Split(rule.Pattern, ",") {
+			if domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "."); domain != "" {
+				c.domains = append(c.domains, domain)
+			}
+		}
+		if len(c.domains) == 0 {
+			return c, fmt.Errorf("no domains")
+		}
+		return c, nil
+	case dbmodels.FilterKindKeyword:
+		for _, keyword := range strings.Split(rule.Pattern, ",") {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				c.keywords = append(c.keywords, keyword)
+			}
+		}
+		if len(c.keywords) == 0 {
+			return c, fmt.Errorf("no keywords")
+		}
+		return c, nil
+	default:
+		return c, fmt.Errorf("unknown kind %q", rule.Kind)
+	}
+}
+
+// ParseFilterSpec interprets "link" as any link, "domain:a.com,b.net" as a
+// domain blocklist, "/regex/" as a regular expression and anything else as
+// comma-separated keywords
+func ParseFilterSpec(spec string) (kind string, pattern string) {
+	spec = strings.TrimSpace(spec)
+	if strings.EqualFold(spec, dbmodels.FilterKindLink) {
+		return dbmodels.FilterKindLink, ""
+	}
+	if domains, ok := strings.CutPrefix(spec, "domain:"); ok {
+		return dbmodels.FilterKindDomain, strings.ToLower(strings.TrimSpace(domains))
+	}
+	if pattern, isRegex := ParsePattern(spec); isRegex {
+		return dbmodels.FilterKindRegex, pattern
+	}
+	return dbmodels.FilterKindKeyword, spec
+}
+
+// AddRule validates, stores and activates a new rule
+func (cf *ContentFilter) AddRule(rule *dbmodels.FilterRule) error {
+	if _, err := compileFilter(*rule); err != nil {
+		return fmt.Errorf("invalid rule: %w", err)
+	}
+	if err := cf.db.CreateFilterRule(rule); err != nil {
+		return err
+	}
+	return cf.Reload()
+}
+
+// DeleteRule removes a rule and deactivates it
+func (cf *ContentFilter) DeleteRule(id uint) error {
+	if err := cf.db.DeleteFilterRule(id); err != nil {
+		return err
+	}
+	return cf.Reload()
+}
+
+// ListRules returns all rules with up-to-date hit counters
+func (cf *ContentFilter) ListRules() ([]dbmodels.FilterRule, error) {
+	return cf.db.GetFilterRules()
+}
+
+// Check returns the first rule the message trips, or nil. The hit is logged
+// and counted against the rule.
+func (cf *ContentFilter) Check(userID int64, msg *models.Message) *dbmodels.FilterRule {
+	text := msg.Text
+	if text == "" {
+		text = msg.Caption
+	}
+	hosts := linkHosts(MessageLinks(msg))
+
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	for i := range cf.rules {
+		if !cf.rules[i].matches(text, hosts) {
+			continue
+		}
+		rule := cf.rules[i].rule
+		log.Printf("Filter rule %d (%s) matched message %d from user %d", rule.ID, rule.Action, msg.ID, userID)
+
+		runes := []rune(text)
+		if len(runes) > filterHitTextLength {
+			runes = runes[:filterHitTextLength]
+		}
+		hit := &dbmodels.FilterHit{RuleID: rule.ID, UserID: userID, Action: rule.Action, Text: string(runes)}
+		if err := cf.db.RecordFilterHit(hit); err != nil {
+			log.Printf("Error recording hit for filter rule %d: %v", rule.ID, err)
+		}
+		return &rule
+	}
+	return nil
+}
+
+// MessageLinks returns the links Telegram detected in a message's text or
+// caption, including the targets of text links
+func MessageLinks(msg *models.Message) []string {
+	text, entities := msg.Text, msg.Entities
+	if text == "" {
+		text, entities = msg.Caption, msg.CaptionEntities
+	}
+
+	var links []string
+	for _, entity := range entities {
+		switch entity.Type {
+		case models.MessageEntityTypeURL:
+			if link := EntityText(text, entity); link != "" {
+				links = append(links, link)
+			}
+		case models.MessageEntityTypeTextLink:
+			links = append(links, entity.URL)
+		}
+	}
+	return links
+}
+
+// EntityText returns the part of text an entity covers. Entity offsets are
+// counted in UTF-16 code units.
+func EntityText(text string, entity models.MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	end := entity.Offset + entity.Length
+	if entity.Offset < 0 || end > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[entity.Offset:end]))
+}
+
+// linkHosts extracts the lowercase host names of links; links without a
+// scheme such as "example.com/path" are accepted
+func linkHosts(links []string) []string {
+	hosts := make([]string, 0, len(links))
+	for _, link := range links {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		if u, err := url.Parse(link); err == nil && u.Hostname() != "" {
+			hosts = append(hosts, strings.ToLower(u.Hostname()))
+		}
+	}
+	return hosts
+}
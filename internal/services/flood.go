@@ -0,0 +1,197 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// FloodVerdict describes how a message relates to what the user sent recently
+type FloodVerdict struct {
+	Flooding bool // more messages within the window than the threshold allows
+
+	// Set when the content repeats an earlier message within the window
+	Duplicate      bool
+	Key            string
+	Count          int // copies seen so far, including this one
+	FirstMessageID int // user chat message ID of the first copy
+	NoteMessageID  int // "×N" note already posted for this content, if any
+}
+
+type duplicateEntry struct {
+	firstMessageID int
+	count          int
+	noteMessageID  int
+	lastSeen       time.Time
+}
+
+type floodState struct {
+	arrivals  []time.Time
+	lastAlbum string
+	seen      map[string]*duplicateEntry
+}
+
+// FloodGuard spots repeated content and message bursts per user within a
+// sliding window. Content is matched by text hash or media file_unique_id.
+type FloodGuard struct {
+	window    time.Duration
+	threshold int // messages per window, 0 = no flood limit
+	mu        sync.Mutex
+	users     map[int64]*floodState
+}
+
+func NewFloodGuard(windowSeconds int, threshold int) *FloodGuard {
+	return &FloodGuard{
+		window:    time.Duration(windowSeconds) * time.Second,
+		threshold: threshold,
+		users:     make(map[int64]*floodState),
+	}
+}
+
+// IsEnabled returns true if duplicate and flood detection is active
+func (fg *FloodGuard) IsEnabled() bool {
+	return fg.window > 0
+}
+
+// Check records the arrival of a message and reports whether it floods or
+// repeats content registered with Remember. Every item of an album counts as
+// one message.
+func (fg *FloodGuard) Check(userID int64, msg *models.Message) FloodVerdict {
+	var verdict FloodVerdict
+	if !fg.IsEnabled() {
+		return verdict
+	}
+
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	now := time.Now()
+	state, ok := fg.users[userID]
+	if !ok {
+		state = &floodState{seen: make(map[string]*duplicateEntry)}
+		fg.users[userID] = state
+	}
+	state.prune(now.Add(-fg.window))
+
+	if msg.MediaGroupID == "" || msg.MediaGroupID != state.lastAlbum {
+		state.arrivals = append(state.arrivals, now)
+	}
+	state.lastAlbum = msg.MediaGroupID
+	verdict.Flooding = fg.threshold > 0 && len(state.arrivals) > fg.threshold
+
+	// Album items are forwarded as a group without per-item mapping, so they
+	// are never collapsed
+	key := ContentKey(msg)
+	if key == "" || msg.MediaGroupID != "" {
+		return verdict
+	}
+
+	entry, ok := state.seen[key]
+	if !ok {
+		return verdict
+	}
+
+	entry.count++
+	entry.lastSeen = now
+	verdict.Duplicate = true
+	verdict.Key = key
+	verdict.Count = entry.count
+	verdict.FirstMessageID = entry.firstMessageID
+	verdict.NoteMessageID = entry.noteMessageID
+	return verdict
+}
+
+// Remember registers the content of a message once it has been forwarded, so
+// later copies within the window can be collapsed onto it. A message that was
+// filtered, rate limited or only auto-answered is never registered.
+func (fg *FloodGuard) Remember(userID int64, msg *models.Message) {
+	key := ContentKey(msg)
+	if !fg.IsEnabled() || key == "" || msg.MediaGroupID != "" {
+		return
+	}
+
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	state, ok := fg.users[userID]
+	if !ok {
+		state = &floodState{seen: make(map[string]*duplicateEntry)}
+		fg.users[userID] = state
+	}
+	state.seen[key] = &duplicateEntry{firstMessageID: msg.ID, count: 1, lastSeen: time.Now()}
+}
+
+// SetNote remembers the "×N" note posted for a duplicated piece of content
+// so later copies update it instead of posting another
+func (fg *FloodGuard) SetNote(userID int64, key string, noteMessageID int) {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	if state, ok := fg.users[userID]; ok {
+		if entry, ok := state.seen[key]; ok {
+			entry.noteMessageID = noteMessageID
+		}
+	}
+}
+
+// Forget drops the history of a user, e.g. once they have been muted
+func (fg *FloodGuard) Forget(userID int64) {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	delete(fg.users, userID)
+}
+
+// CleanupStaleEntries removes users with no activity inside the window
+func (fg *FloodGuard) CleanupStaleEntries() {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	cutoff := time.Now().Add(-fg.window)
+	for userID, state := range fg.users {
+		state.prune(cutoff)
+		if len(state.arrivals) == 0 && len(state.seen) == 0 {
+			delete(fg.users, userID)
+		}
+	}
+}
+
+func (s *floodState) prune(cutoff time.Time) {
+	kept := s.arrivals[:0]
+	for _, t := range s.arrivals {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	s.arrivals = kept
+
+	for key, entry := range s.seen {
+		if !entry.lastSeen.After(cutoff) {
+			delete(s.seen, key)
+		}
+	}
+}
+
+// ContentKey identifies the content of a message: the file_unique_id of its
+// media plus a hash of its caption, or a hash of its trimmed text. Returns ""
+// for other messages.
+func ContentKey(msg *models.Message) string {
+	kind, _, fileUniqueID := DescribeMedia(msg)
+	text := msg.Text
+	if fileUniqueID != "" {
+		kind += ":" + fileUniqueID
+		text = msg.Caption
+	} else {
+		kind = "text"
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" && fileUniqueID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(text))
+	return kind + ":" + hex.EncodeToString(sum[:])
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"sync"
 	"telegram-communication-bot/internal/config"
 	"telegram-communication-bot/internal/database"
 	dbmodels "telegram-communication-bot/internal/models"
@@ -14,9 +16,11 @@ import (
 )
 
 type ForumService struct {
-	bot    *tgbot.Bot
-	config *config.Config
-	db     *database.DB
+	bot           *tgbot.Bot
+	config        *config.Config
+	db            *database.DB
+	reconcileMu   sync.Mutex
+	systemTopicMu sync.Mutex
 }
 
 func NewForumService(bot *tgbot.Bot, config *config.Config, db *database.DB) *ForumService {
@@ -57,6 +61,7 @@ func (fs *ForumService) CreateOrGetForumTopic(ctx context.Context, user *dbmodel
 
 	forumStatus := &dbmodels.ForumStatus{
 		MessageThreadID: messageThreadID,
+		Name:            topicName,
 		Status:          "opened",
 	}
 	if err := fs.db.CreateOrUpdateForumStatus(forumStatus); err != nil {
@@ -66,6 +71,43 @@ func (fs *ForumService) CreateOrGetForumTopic(ctx context.Context, user *dbmodel
 	return messageThreadID, true, nil
 }
 
+// EnsureSystemTopic returns the bot-owned topic stored under settingKey,
+// creating it with the given name on first use. System topics belong to no
+// user and get no ForumStatus row, so reconcile leaves them alone.
+func (fs *ForumService) EnsureSystemTopic(ctx context.Context, settingKey string, name string) (int, error) {
+	if !fs.config.HasAdminGroup() {
+		return 0, fmt.Errorf("admin group not configured")
+	}
+
+	fs.systemTopicMu.Lock()
+	defer fs.systemTopicMu.Unlock()
+
+	if value, err := fs.db.GetSetting(settingKey); err == nil {
+		if threadID, err := strconv.Atoi(value); err == nil && threadID != 0 {
+			return threadID, nil
+		}
+	}
+
+	topic, err := fs.bot.CreateForumTopic(ctx, &tgbot.CreateForumTopicParams{
+		ChatID: fs.config.AdminGroupID,
+		Name:   name,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create forum topic: %w", err)
+	}
+
+	if err := fs.db.SetSetting(settingKey, strconv.Itoa(topic.MessageThreadID)); err != nil {
+		log.Printf("Error saving %s: %v", settingKey, err)
+	}
+	return topic.MessageThreadID, nil
+}
+
+// ResetSystemTopic forgets a system topic that has been deleted so the next
+// EnsureSystemTopic call creates a fresh one
+func (fs *ForumService) ResetSystemTopic(settingKey string) error {
+	return fs.db.SetSetting(settingKey, "")
+}
+
 func (fs *ForumService) CloseForumTopic(ctx context.Context, messageThreadID int) error {
 	if !fs.config.HasAdminGroup() {
 		return fmt.Errorf("admin group not configured")
@@ -154,6 +196,36 @@ func (fs *ForumService) HandleForumStatusChange(messageThreadID int, newStatus s
 	return fs.db.CreateOrUpdateForumStatus(forumStatus)
 }
 
+// HandleTopicRenamed records a topic name change made from the Telegram UI
+func (fs *ForumService) HandleTopicRenamed(messageThreadID int, name string) error {
+	return fs.db.SetForumTopicName(messageThreadID, name)
+}
+
+// HandleTopicDeleted applies the delete-topic policy once a user's topic has
+// disappeared. With DeleteTopicAsForeverBan the user is banned; in every case
+// the stale thread ID is reset. Returns whether the user was banned.
+func (fs *ForumService) HandleTopicDeleted(userID int64) (bool, error) {
+	if err := fs.ResetUserThreadID(userID); err != nil {
+		return false, err
+	}
+
+	if !fs.config.DeleteTopicAsForeverBan {
+		return false, nil
+	}
+
+	banStatus := &dbmodels.BanStatus{
+		UserID:   userID,
+		IsBanned: true,
+		Reason:   "Forum topic deleted",
+	}
+	if err := fs.db.CreateOrUpdateBanStatus(banStatus); err != nil {
+		return false, fmt.Errorf("failed to ban user %d: %w", userID, err)
+	}
+
+	log.Printf("User %d banned after their forum topic was deleted", userID)
+	return true, nil
+}
+
 func (fs *ForumService) GetUserByThreadID(messageThreadID int) (*dbmodels.User, error) {
 	var user dbmodels.User
 	err := fs.db.DB.Where("message_thread_id = ?", messageThreadID).First(&user).Error
@@ -221,7 +293,10 @@ func (fs *ForumService) ResetUserThreadID(userID int64) error {
 
 	err := fs.db.DB.Model(&dbmodels.User{}).
 		Where("user_id = ?", userID).
-		Update("message_thread_id", 0).Error
+		Updates(map[string]interface{}{
+			"message_thread_id": 0,
+			"card_message_id":   0,
+		}).Error
 	if err != nil {
 		return fmt.Errorf("failed to reset user thread ID: %w", err)
 	}
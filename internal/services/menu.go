@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"telegram-communication-bot/internal/i18n"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// Menu callback data prefixes; the menu path follows the prefix
+const (
+	MenuCallbackOpen  = "menu_open_"
+	MenuCallbackHuman = "menu_human_"
+)
+
+// menuPathSeparator joins node IDs into a path such as "pricing.plans"
+const menuPathSeparator = "."
+
+// maxCallbackDataLength is Telegram's limit for inline button callback data
+const maxCallbackDataLength = 64
+
+// MenuNode is one level of the self-service menu. Leaf nodes just show Text;
+// nodes with Items also render their children as buttons.
+type MenuNode struct {
+	ID    string      `json:"id"`
+	Label string      `json:"label"`
+	Text  string      `json:"text"`
+	Items []*MenuNode `json:"items"`
+}
+
+// MenuService serves a tree of inline-keyboard menus loaded from a JSON file
+type MenuService struct {
+	root    *MenuNode
+	catalog *i18n.Catalog
+}
+
+// NewMenuService loads the menu tree from path. An empty path disables the menu.
+func NewMenuService(path string, catalog *i18n.Catalog) (*MenuService, error) {
+	ms := &MenuService{catalog: catalog}
+	if path == "" {
+		return ms, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read menu file: %w", err)
+	}
+
+	var root MenuNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse menu file: %w", err)
+	}
+	if err := validateMenuNode(&root, ""); err != nil {
+		return nil, fmt.Errorf("invalid menu file: %w", err)
+	}
+
+	ms.root = &root
+	return ms, nil
+}
+
+func validateMenuNode(node *MenuNode, path string) error {
+	seen := make(map[string]bool)
+	for _, item := range node.Items {
+		if item.ID == "" || strings.Contains(item.ID, menuPathSeparator) {
+			return fmt.Errorf("menu item %q under %q needs an id without %q", item.Label, path, menuPathSeparator)
+		}
+		if seen[item.ID] {
+			return fmt.Errorf("duplicate menu id %q under %q", item.ID, path)
+		}
+		seen[item.ID] = true
+		if item.Label == "" {
+			return fmt.Errorf("menu item %q needs a label", item.ID)
+		}
+
+		childPath := joinMenuPath(path, item.ID)
+		if len(MenuCallbackHuman)+len(childPath) > maxCallbackDataLength {
+			return fmt.Errorf("menu path %q is too long for callback data", childPath)
+		}
+		if err := validateMenuNode(item, childPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func joinMenuPath(parent, id string) string {
+	if parent == "" {
+		return id
+	}
+	return parent + menuPathSeparator + id
+}
+
+// IsEnabled returns true if a menu file was loaded
+func (ms *MenuService) IsEnabled() bool {
+	return ms.root != nil
+}
+
+// Find resolves a path to its node; the empty path is the root menu
+func (ms *MenuService) Find(path string) (*MenuNode, bool) {
+	if ms.root == nil {
+		return nil, false
+	}
+
+	node := ms.root
+	if path == "" {
+		return node, true
+	}
+	for _, id := range strings.Split(path, menuPathSeparator) {
+		var next *MenuNode
+		for _, item := range node.Items {
+			if item.ID == id {
+				next = item
+				break
+			}
+		}
+		if next == nil {
+			return nil, false
+		}
+		node = next
+	}
+	return node, true
+}
+
+// Breadcrumb renders a path as the labels the user clicked, e.g. "价格 › 套餐"
+func (ms *MenuService) Breadcrumb(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	var labels []string
+	ids := strings.Split(path, menuPathSeparator)
+	for i := range ids {
+		node, ok := ms.Find(strings.Join(ids[:i+1], menuPathSeparator))
+		if !ok {
+			break
+		}
+		labels = append(labels, node.Label)
+	}
+	return strings.Join(labels, " › ")
+}
+
+// Keyboard builds the buttons for the node at path: its children, a
+// "talk to a human" button, and a back button below the root.
+func (ms *MenuService) Keyboard(locale, path string, node *MenuNode) *models.InlineKeyboardMarkup {
+	var rows [][]models.InlineKeyboardButton
+	for _, item := range node.Items {
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: item.Label, CallbackData: MenuCallbackOpen + joinMenuPath(path, item.ID)},
+		})
+	}
+
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: ms.catalog.T(locale, "menu.button_human"), CallbackData: MenuCallbackHuman + path},
+	})
+
+	if path != "" {
+		parent := ""
+		if i := strings.LastIndex(path, menuPathSeparator); i != -1 {
+			parent = path[:i]
+		}
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: ms.catalog.T(locale, "menu.button_back"), CallbackData: MenuCallbackOpen + parent},
+		})
+	}
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"fmt"
+	"html"
 	"log"
 	"strings"
 	"sync"
 	"telegram-communication-bot/internal/database"
+	"telegram-communication-bot/internal/i18n"
 	dbmodels "telegram-communication-bot/internal/models"
 	"time"
 
@@ -16,12 +18,14 @@ import (
 
 type MessageService struct {
 	db                  *database.DB
+	catalog             *i18n.Catalog
 	mediaGroupScheduled sync.Map
 }
 
-func NewMessageService(db *database.DB) *MessageService {
+func NewMessageService(db *database.DB, catalog *i18n.Catalog) *MessageService {
 	return &MessageService{
-		db: db,
+		db:      db,
+		catalog: catalog,
 	}
 }
 
@@ -42,102 +46,114 @@ func (ms *MessageService) GetGroupMessageFromUser(userChatMessageID int, userID
 	return ms.db.GetMessageMapByUserMessage(userChatMessageID, userID)
 }
 
-// copyMessage copies a message to a target chat, optionally into a forum thread.
-func (ms *MessageService) copyMessage(ctx context.Context, b *tgbot.Bot, fromMessage *models.Message, toChatID int64, threadID int) (*models.Message, error) {
+// copyMessage copies a message to a target chat, optionally into a forum
+// thread; silent copies arrive without a notification.
+func (ms *MessageService) copyMessage(ctx context.Context, b *tgbot.Bot, fromMessage *models.Message, toChatID int64, threadID int, silent bool) (*models.Message, error) {
 	switch {
 	case fromMessage.Text != "":
 		return b.SendMessage(ctx, &tgbot.SendMessageParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Text:            fromMessage.Text,
-			Entities:        fromMessage.Entities,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Text:                fromMessage.Text,
+			Entities:            fromMessage.Entities,
 		})
 
 	case len(fromMessage.Photo) > 0:
 		largest := fromMessage.Photo[len(fromMessage.Photo)-1]
 		return b.SendPhoto(ctx, &tgbot.SendPhotoParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Photo:           &models.InputFileString{Data: largest.FileID},
-			Caption:         fromMessage.Caption,
-			CaptionEntities: fromMessage.CaptionEntities,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Photo:               &models.InputFileString{Data: largest.FileID},
+			Caption:             fromMessage.Caption,
+			CaptionEntities:     fromMessage.CaptionEntities,
 		})
 
 	case fromMessage.Document != nil:
 		return b.SendDocument(ctx, &tgbot.SendDocumentParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Document:        &models.InputFileString{Data: fromMessage.Document.FileID},
-			Caption:         fromMessage.Caption,
-			CaptionEntities: fromMessage.CaptionEntities,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Document:            &models.InputFileString{Data: fromMessage.Document.FileID},
+			Caption:             fromMessage.Caption,
+			CaptionEntities:     fromMessage.CaptionEntities,
 		})
 
 	case fromMessage.Video != nil:
 		return b.SendVideo(ctx, &tgbot.SendVideoParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Video:           &models.InputFileString{Data: fromMessage.Video.FileID},
-			Caption:         fromMessage.Caption,
-			CaptionEntities: fromMessage.CaptionEntities,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Video:               &models.InputFileString{Data: fromMessage.Video.FileID},
+			Caption:             fromMessage.Caption,
+			CaptionEntities:     fromMessage.CaptionEntities,
 		})
 
 	case fromMessage.Audio != nil:
 		return b.SendAudio(ctx, &tgbot.SendAudioParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Audio:           &models.InputFileString{Data: fromMessage.Audio.FileID},
-			Caption:         fromMessage.Caption,
-			CaptionEntities: fromMessage.CaptionEntities,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Audio:               &models.InputFileString{Data: fromMessage.Audio.FileID},
+			Caption:             fromMessage.Caption,
+			CaptionEntities:     fromMessage.CaptionEntities,
 		})
 
 	case fromMessage.Voice != nil:
 		return b.SendVoice(ctx, &tgbot.SendVoiceParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Voice:           &models.InputFileString{Data: fromMessage.Voice.FileID},
-			Caption:         fromMessage.Caption,
-			CaptionEntities: fromMessage.CaptionEntities,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Voice:               &models.InputFileString{Data: fromMessage.Voice.FileID},
+			Caption:             fromMessage.Caption,
+			CaptionEntities:     fromMessage.CaptionEntities,
 		})
 
 	case fromMessage.VideoNote != nil:
 		return b.SendVideoNote(ctx, &tgbot.SendVideoNoteParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			VideoNote:       &models.InputFileString{Data: fromMessage.VideoNote.FileID},
-			Length:          fromMessage.VideoNote.Length,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			VideoNote:           &models.InputFileString{Data: fromMessage.VideoNote.FileID},
+			Length:              fromMessage.VideoNote.Length,
 		})
 
 	case fromMessage.Sticker != nil:
 		return b.SendSticker(ctx, &tgbot.SendStickerParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Sticker:         &models.InputFileString{Data: fromMessage.Sticker.FileID},
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Sticker:             &models.InputFileString{Data: fromMessage.Sticker.FileID},
 		})
 
 	case fromMessage.Animation != nil:
 		return b.SendAnimation(ctx, &tgbot.SendAnimationParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Animation:       &models.InputFileString{Data: fromMessage.Animation.FileID},
-			Caption:         fromMessage.Caption,
-			CaptionEntities: fromMessage.CaptionEntities,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Animation:           &models.InputFileString{Data: fromMessage.Animation.FileID},
+			Caption:             fromMessage.Caption,
+			CaptionEntities:     fromMessage.CaptionEntities,
 		})
 
 	case fromMessage.Location != nil:
 		return b.SendLocation(ctx, &tgbot.SendLocationParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			Latitude:        fromMessage.Location.Latitude,
-			Longitude:       fromMessage.Location.Longitude,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			Latitude:            fromMessage.Location.Latitude,
+			Longitude:           fromMessage.Location.Longitude,
 		})
 
 	case fromMessage.Contact != nil:
 		return b.SendContact(ctx, &tgbot.SendContactParams{
-			ChatID:          toChatID,
-			MessageThreadID: threadID,
-			PhoneNumber:     fromMessage.Contact.PhoneNumber,
-			FirstName:       fromMessage.Contact.FirstName,
-			LastName:        fromMessage.Contact.LastName,
+			ChatID:              toChatID,
+			MessageThreadID:     threadID,
+			DisableNotification: silent,
+			PhoneNumber:         fromMessage.Contact.PhoneNumber,
+			FirstName:           fromMessage.Contact.FirstName,
+			LastName:            fromMessage.Contact.LastName,
 		})
 
 	default:
@@ -145,16 +161,16 @@ func (ms *MessageService) copyMessage(ctx context.Context, b *tgbot.Bot, fromMes
 	}
 }
 
-func (ms *MessageService) ForwardMessageToGroup(ctx context.Context, b *tgbot.Bot, fromMessage *models.Message, groupChatID int64, messageThreadID int) (*models.Message, error) {
-	return ms.copyMessage(ctx, b, fromMessage, groupChatID, messageThreadID)
+func (ms *MessageService) ForwardMessageToGroup(ctx context.Context, b *tgbot.Bot, fromMessage *models.Message, groupChatID int64, messageThreadID int, silent bool) (*models.Message, error) {
+	return ms.copyMessage(ctx, b, fromMessage, groupChatID, messageThreadID, silent)
 }
 
 func (ms *MessageService) ForwardMessageToUser(ctx context.Context, b *tgbot.Bot, fromMessage *models.Message, userChatID int64) (*models.Message, error) {
-	return ms.copyMessage(ctx, b, fromMessage, userChatID, 0)
+	return ms.copyMessage(ctx, b, fromMessage, userChatID, 0, false)
 }
 
 // HandleMediaGroup processes media group messages with deduplication.
-func (ms *MessageService) HandleMediaGroup(ctx context.Context, b *tgbot.Bot, message *models.Message, groupChatID int64, messageThreadID int) {
+func (ms *MessageService) HandleMediaGroup(ctx context.Context, b *tgbot.Bot, message *models.Message, groupChatID int64, messageThreadID int, silent bool) {
 	if message.MediaGroupID == "" {
 		return
 	}
@@ -175,12 +191,12 @@ func (ms *MessageService) HandleMediaGroup(ctx context.Context, b *tgbot.Bot, me
 		go func() {
 			time.Sleep(3 * time.Second)
 			defer ms.mediaGroupScheduled.Delete(message.MediaGroupID)
-			ms.processMediaGroup(context.Background(), b, message.MediaGroupID, groupChatID, messageThreadID)
+			ms.processMediaGroup(context.Background(), b, message.MediaGroupID, groupChatID, messageThreadID, silent)
 		}()
 	}
 }
 
-func (ms *MessageService) processMediaGroup(ctx context.Context, b *tgbot.Bot, mediaGroupID string, groupChatID int64, messageThreadID int) {
+func (ms *MessageService) processMediaGroup(ctx context.Context, b *tgbot.Bot, mediaGroupID string, groupChatID int64, messageThreadID int, silent bool) {
 	messages, err := ms.db.GetMediaGroupMessages(mediaGroupID)
 	if err != nil {
 		log.Printf("Error getting media group messages: %v", err)
@@ -197,10 +213,11 @@ func (ms *MessageService) processMediaGroup(ctx context.Context, b *tgbot.Bot, m
 	}
 
 	_, err = b.CopyMessages(ctx, &tgbot.CopyMessagesParams{
-		ChatID:          groupChatID,
-		FromChatID:      messages[0].ChatID,
-		MessageIDs:      messageIDs,
-		MessageThreadID: messageThreadID,
+		ChatID:              groupChatID,
+		FromChatID:          messages[0].ChatID,
+		MessageIDs:          messageIDs,
+		MessageThreadID:     messageThreadID,
+		DisableNotification: silent,
 	})
 	if err != nil {
 		log.Printf("Error copying media group messages: %v", err)
@@ -222,53 +239,129 @@ func (ms *MessageService) RecordUserMessage(userID int64, chatID int64, messageI
 	return ms.db.CreateUserMessage(userMessage)
 }
 
-func (ms *MessageService) SendUserInfoMessage(ctx context.Context, b *tgbot.Bot, user *dbmodels.User, groupChatID int64, messageThreadID int) (*models.Message, error) {
-	var infoText strings.Builder
-	infoText.WriteString("📋 <b>用户信息</b>\n\n")
-
-	if user.Username != "" {
-		infoText.WriteString(fmt.Sprintf("👤 用户名: @%s\n", user.Username))
-	} else {
-		infoText.WriteString("👤 用户名: <i>无</i>\n")
-	}
-	infoText.WriteString(fmt.Sprintf("🆔 用户ID: <code>%d</code>", user.UserID))
-
+// SendContactCard posts the user card with its action buttons into the user's topic.
+func (ms *MessageService) SendContactCard(ctx context.Context, b *tgbot.Bot, user *dbmodels.User, groupChatID int64, messageThreadID int) (*models.Message, error) {
+	text, keyboard := ms.buildContactCard(user)
 	return b.SendMessage(ctx, &tgbot.SendMessageParams{
 		ChatID:          groupChatID,
 		MessageThreadID: messageThreadID,
-		Text:            infoText.String(),
+		Text:            text,
 		ParseMode:       models.ParseModeHTML,
+		ReplyMarkup:     keyboard,
 	})
 }
 
-func (ms *MessageService) SendContactCard(ctx context.Context, b *tgbot.Bot, user *dbmodels.User, groupChatID int64, messageThreadID int) (*models.Message, error) {
+// UpdateContactCard edits the pinned user card in place. It is a no-op if the
+// user has no card yet.
+func (ms *MessageService) UpdateContactCard(ctx context.Context, b *tgbot.Bot, user *dbmodels.User, groupChatID int64) error {
+	if user.CardMessageID == 0 {
+		return nil
+	}
+
+	text, keyboard := ms.buildContactCard(user)
+	_, err := b.EditMessageText(ctx, &tgbot.EditMessageTextParams{
+		ChatID:      groupChatID,
+		MessageID:   user.CardMessageID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil && strings.Contains(err.Error(), "message is not modified") {
+		return nil
+	}
+	return err
+}
+
+func (ms *MessageService) buildContactCard(user *dbmodels.User) (string, models.InlineKeyboardMarkup) {
 	var cardText strings.Builder
-	cardText.WriteString("👤 <b>用户信息</b>\n\n")
-	cardText.WriteString(fmt.Sprintf("🆔 <b>用户ID:</b> <code>%d</code>\n", user.UserID))
-	cardText.WriteString(fmt.Sprintf("👤 <b>姓名:</b> %s", user.FirstName))
+	cardText.WriteString(ms.catalog.Admin("contact.title"))
+	cardText.WriteString(ms.catalog.Admin("contact.user_id", user.UserID))
+	cardText.WriteString(ms.catalog.Admin("contact.name", html.EscapeString(user.FirstName)))
 
 	if user.LastName != "" {
-		cardText.WriteString(" " + user.LastName)
+		cardText.WriteString(" " + html.EscapeString(user.LastName))
 	}
 	cardText.WriteString("\n")
 
 	if user.Username != "" {
-		cardText.WriteString(fmt.Sprintf("📱 <b>用户名:</b> @%s\n", user.Username))
+		cardText.WriteString(ms.catalog.Admin("contact.username", user.Username))
 	}
 
 	if user.IsPremium {
 		cardText.WriteString("⭐ <b>Telegram Premium</b>\n")
 	}
 
-	cardText.WriteString(fmt.Sprintf("📅 <b>首次联系:</b> %s\n", user.CreatedAt.Format("2006-01-02 15:04:05")))
-	cardText.WriteString(fmt.Sprintf("🔄 <b>最后活跃:</b> %s", user.UpdatedAt.Format("2006-01-02 15:04:05")))
+	banned := ms.db.IsUserBanned(user.UserID)
+	if banned {
+		cardText.WriteString(ms.catalog.Admin("contact.status_banned"))
+	} else if ms.db.IsUserShadowBanned(user.UserID) {
+		cardText.WriteString(ms.catalog.Admin("contact.status_shadow_banned"))
+	} else {
+		cardText.WriteString(ms.catalog.Admin("contact.status_ok"))
+	}
 
-	return b.SendMessage(ctx, &tgbot.SendMessageParams{
-		ChatID:          groupChatID,
-		MessageThreadID: messageThreadID,
-		Text:            cardText.String(),
-		ParseMode:       models.ParseModeHTML,
-	})
+	if user.Verified {
+		cardText.WriteString(ms.catalog.Admin("contact.verified"))
+	} else {
+		cardText.WriteString(ms.catalog.Admin("contact.unverified"))
+	}
+
+	if user.Trusted {
+		cardText.WriteString(ms.catalog.Admin("contact.trusted"))
+	}
+
+	if mute, err := ms.db.GetActiveMute(user.UserID); err == nil {
+		cardText.WriteString(ms.catalog.Admin("contact.muted", mute.MutedUntil.Format("2006-01-02 15:04")))
+	}
+
+	if user.AssignedAgentID != 0 {
+		if agent, err := ms.db.GetAgent(user.AssignedAgentID); err == nil {
+			cardText.WriteString(ms.catalog.Admin("contact.agent", html.EscapeString(AgentDisplayName(agent))))
+		}
+	}
+
+	if tags, err := ms.db.GetUserTags(user.UserID); err == nil && len(tags) > 0 {
+		cardText.WriteString(ms.catalog.Admin("contact.tags", html.EscapeString("#"+strings.Join(tags, " #"))))
+	}
+
+	if attributes, err := ms.db.GetUserAttributes(user.UserID); err == nil {
+		for _, attr := range attributes {
+			cardText.WriteString(fmt.Sprintf("📎 <b>%s:</b> %s\n", html.EscapeString(attr.Key), html.EscapeString(attr.Value)))
+		}
+	}
+
+	lastActive := user.LastActiveAt
+	if lastActive.IsZero() {
+		lastActive = user.UpdatedAt
+	}
+
+	cardText.WriteString(ms.catalog.Admin("contact.messages", user.MessageCount))
+	cardText.WriteString(ms.catalog.Admin("contact.first_contact", user.CreatedAt.Format("2006-01-02 15:04:05")))
+	cardText.WriteString(ms.catalog.Admin("contact.last_active", lastActive.Format("2006-01-02 15:04:05")))
+
+	return cardText.String(), ms.ContactCardKeyboard(user.UserID, banned)
+}
+
+// ContactCardKeyboard returns the action buttons shown under a user card.
+// Callback data has the form card_<action>_<user_id>.
+func (ms *MessageService) ContactCardKeyboard(userID int64, banned bool) models.InlineKeyboardMarkup {
+	banButton := models.InlineKeyboardButton{Text: ms.catalog.Admin("contact.button_ban"), CallbackData: fmt.Sprintf("card_ban_%d", userID)}
+	if banned {
+		banButton = models.InlineKeyboardButton{Text: ms.catalog.Admin("contact.button_unban"), CallbackData: fmt.Sprintf("card_unban_%d", userID)}
+	}
+
+	return models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				banButton,
+				{Text: ms.catalog.Admin("contact.button_close"), CallbackData: fmt.Sprintf("card_close_%d", userID)},
+				{Text: ms.catalog.Admin("contact.button_reset"), CallbackData: fmt.Sprintf("card_reset_%d", userID)},
+			},
+			{
+				{Text: ms.catalog.Admin("contact.button_history"), CallbackData: fmt.Sprintf("card_history_%d", userID)},
+			},
+		},
+	}
 }
 
 func (ms *MessageService) PinMessage(ctx context.Context, b *tgbot.Bot, chatID int64, messageID int) error {
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"telegram-communication-bot/internal/config"
+	"telegram-communication-bot/internal/database"
+	"telegram-communication-bot/internal/i18n"
+	dbmodels "telegram-communication-bot/internal/models"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+// ReviewCallbackPrefix starts review decision callback data:
+// review_<approve|reject|ban>_<heldID>
+const ReviewCallbackPrefix = "review_"
+
+// reviewTopicSetting stores the thread ID of the review topic
+const reviewTopicSetting = "review_topic_id"
+
+var ErrAlreadyReviewed = errors.New("held message already reviewed")
+
+// ModerationQueue keeps held user messages in a dedicated review topic of the
+// admin group, where moderators approve, reject or ban with one tap
+type ModerationQueue struct {
+	bot      *tgbot.Bot
+	config   *config.Config
+	db       *database.DB
+	catalog  *i18n.Catalog
+	forum    *ForumService
+	messages *MessageService
+}
+
+func NewModerationQueue(bot *tgbot.Bot, config *config.Config, db *database.DB, catalog *i18n.Catalog, forum *ForumService, messages *MessageService) *ModerationQueue {
+	return &ModerationQueue{
+		bot:      bot,
+		config:   config,
+		db:       db,
+		catalog:  catalog,
+		forum:    forum,
+		messages: messages,
+	}
+}
+
+// Hold stores a message for review and posts it with the decision buttons
+// into the review topic, recreating the topic if it has been deleted. If the
+// message cannot be posted the row is marked failed and an error returned,
+// so the caller can deliver the message another way.
+func (mq *ModerationQueue) Hold(ctx context.Context, msg *models.Message, user *dbmodels.User, kind string, reason string) (*dbmodels.HeldMessage, error) {
+	if !mq.config.HasAdminGroup() {
+		return nil, fmt.Errorf("admin group not configured")
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	held := &dbmodels.HeldMessage{
+		UserID:  user.UserID,
+		Kind:    kind,
+		Reason:  reason,
+		Payload: string(payload),
+		Status:  dbmodels.HeldStatusPending,
+	}
+	if err := mq.db.SaveHeldMessage(held); err != nil {
+		return nil, fmt.Errorf("failed to store held message: %w", err)
+	}
+
+	if err := mq.post(ctx, msg, user, held); err != nil {
+		if resolveErr := mq.db.ResolveHeldMessage(held.ID, dbmodels.HeldStatusFailed, 0); resolveErr != nil {
+			log.Printf("Error marking held message %d as failed: %v", held.ID, resolveErr)
+		}
+		return nil, err
+	}
+	return held, nil
+}
+
+// post copies a held message into the review topic and attaches the card
+// with the decision buttons to it
+func (mq *ModerationQueue) post(ctx context.Context, msg *models.Message, user *dbmodels.User, held *dbmodels.HeldMessage) error {
+	const maxAttempts = 2
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		threadID, err := mq.forum.EnsureSystemTopic(ctx, reviewTopicSetting, mq.catalog.Admin("review.topic_name"))
+		if err != nil {
+			return err
+		}
+
+		copied, err := mq.messages.ForwardMessageToGroup(ctx, mq.bot, msg, mq.config.AdminGroupID, threadID, false)
+		if err != nil {
+			if IsThreadNotFoundError(err) && attempt < maxAttempts-1 {
+				log.Printf("Review topic %d not found, recreating", threadID)
+				if err := mq.forum.ResetSystemTopic(reviewTopicSetting); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("failed to copy message to review topic: %w", err)
+		}
+
+		card, err := mq.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+			ChatID:          mq.config.AdminGroupID,
+			MessageThreadID: threadID,
+			Text:            mq.CardText(held, user),
+			ParseMode:       models.ParseModeHTML,
+			ReplyParameters: &models.ReplyParameters{MessageID: copied.ID},
+			ReplyMarkup:     mq.keyboard(held.ID),
+		})
+		if err != nil {
+			if _, delErr := mq.bot.DeleteMessage(ctx, &tgbot.DeleteMessageParams{
+				ChatID:    mq.config.AdminGroupID,
+				MessageID: copied.ID,
+			}); delErr != nil {
+				log.Printf("Error removing review copy %d: %v", copied.ID, delErr)
+			}
+			return fmt.Errorf("failed to send review card: %w", err)
+		}
+
+		held.ReviewMessageID = card.ID
+		if err := mq.db.SaveHeldMessage(held); err != nil {
+			log.Printf("Error saving review card for held message %d: %v", held.ID, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// Resolve records a moderator's decision on a pending held message and
+// returns it with the original message decoded
+func (mq *ModerationQueue) Resolve(id uint, status string, reviewerID int64) (*dbmodels.HeldMessage, *models.Message, error) {
+	if err := mq.db.ResolveHeldMessage(id, status, reviewerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrAlreadyReviewed
+		}
+		return nil, nil, err
+	}
+
+	held, err := mq.db.GetHeldMessage(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var msg models.Message
+	if err := json.Unmarshal([]byte(held.Payload), &msg); err != nil {
+		return held, nil, fmt.Errorf("failed to decode held message %d: %w", id, err)
+	}
+	return held, &msg, nil
+}
+
+// ParseReviewCallback splits review callback data into decision and held ID
+func ParseReviewCallback(data string) (string, uint, bool) {
+	action, idStr, ok := strings.Cut(strings.TrimPrefix(data, ReviewCallbackPrefix), "_")
+	if !ok {
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	switch action {
+	case dbmodels.HeldStatusApproved, dbmodels.HeldStatusRejected, dbmodels.HeldStatusBanned:
+		return action, uint(id), true
+	}
+	return "", 0, false
+}
+
+// CardText renders the review card shown above the decision buttons
+func (mq *ModerationQueue) CardText(held *dbmodels.HeldMessage, user *dbmodels.User) string {
+	name := user.FirstName
+	if user.LastName != "" {
+		name += " " + user.LastName
+	}
+	return mq.catalog.Admin("review.card", held.ID, html.EscapeString(name), user.UserID, html.EscapeString(held.Reason))
+}
+
+func (mq *ModerationQueue) keyboard(id uint) models.InlineKeyboardMarkup {
+	button := func(key string, status string) models.InlineKeyboardButton {
+		return models.InlineKeyboardButton{
+			Text:         mq.catalog.Admin(key),
+			CallbackData: fmt.Sprintf("%s%s_%d", ReviewCallbackPrefix, status, id),
+		}
+	}
+	return models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{{
+			button("review.approve_button", dbmodels.HeldStatusApproved),
+			button("review.reject_button", dbmodels.HeldStatusRejected),
+			button("review.ban_button", dbmodels.HeldStatusBanned),
+		}},
+	}
+}
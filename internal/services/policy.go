@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"telegram-communication-bot/internal/config"
+	dbmodels "telegram-communication-bot/internal/models"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// Message features new users can be restricted from sending
+const (
+	PolicyForward        = "forward"         // any forwarded message
+	PolicyForwardChannel = "forward_channel" // posts forwarded from a channel
+	PolicyURL            = "url"
+	PolicyTextLink       = "text_link"
+	PolicyMention        = "mention"
+)
+
+var policyFeatures = []string{PolicyForward, PolicyForwardChannel, PolicyURL, PolicyTextLink, PolicyMention}
+
+// PolicyEngine holds back forwards, links and mentions from users who are
+// neither trusted by an admin nor established: a user is established once
+// they have sent enough messages or, if a minimum age is set, their first
+// contact is old enough.
+type PolicyEngine struct {
+	config     *config.Config
+	restricted map[string]bool
+}
+
+func NewPolicyEngine(cfg *config.Config) (*PolicyEngine, error) {
+	restricted := make(map[string]bool, len(cfg.NewUserRestrictions))
+	for _, feature := range cfg.NewUserRestrictions {
+		if !isPolicyFeature(feature) {
+			return nil, fmt.Errorf("invalid NEW_USER_RESTRICTIONS: unknown feature %q", feature)
+		}
+		restricted[feature] = true
+	}
+	return &PolicyEngine{
+		config:     cfg,
+		restricted: restricted,
+	}, nil
+}
+
+func isPolicyFeature(feature string) bool {
+	for _, f := range policyFeatures {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEnabled returns true if any feature is restricted
+func (pe *PolicyEngine) IsEnabled() bool {
+	return len(pe.restricted) > 0
+}
+
+// Check returns the restricted feature a message uses if the sender is
+// still subject to restrictions, or "" if the message may pass
+func (pe *PolicyEngine) Check(user *dbmodels.User, msg *models.Message) string {
+	if !pe.IsEnabled() {
+		return ""
+	}
+
+	var feature string
+	for _, f := range MessageFeatures(msg) {
+		if pe.restricted[f] {
+			feature = f
+			break
+		}
+	}
+	if feature == "" || !pe.IsRestricted(user) {
+		return ""
+	}
+
+	log.Printf("Policy restricted %s in message %d from user %d", feature, msg.ID, user.UserID)
+	return feature
+}
+
+// IsRestricted reports whether a user is still treated as new
+func (pe *PolicyEngine) IsRestricted(user *dbmodels.User) bool {
+	if user.Trusted {
+		return false
+	}
+
+	// MessageCount only counts messages relayed to agents, so held, filtered
+	// and withheld messages never help a user out of restrictions
+	if user.MessageCount >= pe.config.NewUserMinMessages {
+		return false
+	}
+
+	if pe.config.NewUserMinAgeHours == 0 {
+		return true
+	}
+	minAge := time.Duration(pe.config.NewUserMinAgeHours) * time.Hour
+	return time.Since(user.CreatedAt) < minAge
+}
+
+// MessageFeatures lists the policy features a message uses
+func MessageFeatures(msg *models.Message) []string {
+	var features []string
+
+	if msg.ForwardOrigin != nil {
+		features = append(features, PolicyForward)
+		if msg.ForwardOrigin.Type == models.MessageOriginTypeChannel {
+			features = append(features, PolicyForwardChannel)
+		}
+	}
+
+	entities := msg.Entities
+	if msg.Text == "" {
+		entities = msg.CaptionEntities
+	}
+	seen := make(map[string]bool)
+	for _, entity := range entities {
+		var feature string
+		switch entity.Type {
+		case models.MessageEntityTypeURL:
+			feature = PolicyURL
+		case models.MessageEntityTypeTextLink:
+			feature = PolicyTextLink
+		case models.MessageEntityTypeMention, models.MessageEntityTypeTextMention:
+			feature = PolicyMention
+		}
+		if feature != "" && !seen[feature] {
+			seen[feature] = true
+			features = append(features, feature)
+		}
+	}
+	return features
+}
@@ -1,8 +1,8 @@
 package services
 
 import (
-	"fmt"
 	"sync"
+	"telegram-communication-bot/internal/i18n"
 	"time"
 )
 
@@ -10,12 +10,14 @@ type RateLimiter struct {
 	interval int // seconds between messages
 	mu       sync.Mutex
 	lastMsg  map[int64]time.Time
+	catalog  *i18n.Catalog
 }
 
-func NewRateLimiter(interval int) *RateLimiter {
+func NewRateLimiter(interval int, catalog *i18n.Catalog) *RateLimiter {
 	return &RateLimiter{
 		interval: interval,
 		lastMsg:  make(map[int64]time.Time),
+		catalog:  catalog,
 	}
 }
 
@@ -41,25 +43,25 @@ func (rl *RateLimiter) CheckAndRecord(userID int64) (bool, time.Duration) {
 	return true, 0
 }
 
-// FormatCooldownMessage returns a formatted message about the cooldown
-func (rl *RateLimiter) FormatCooldownMessage(waitTime time.Duration) string {
+// FormatCooldownMessage returns a formatted message about the cooldown in the given locale
+func (rl *RateLimiter) FormatCooldownMessage(locale string, waitTime time.Duration) string {
 	seconds := int(waitTime.Seconds())
 	if seconds <= 0 {
-		return "您可以立即发送消息。"
+		return rl.catalog.T(locale, "ratelimit.now")
 	}
 
 	if seconds < 60 {
-		return fmt.Sprintf("⏰ 请等待 %d 秒后再发送消息", seconds)
+		return rl.catalog.T(locale, "ratelimit.seconds", seconds)
 	}
 
 	minutes := seconds / 60
 	remainingSeconds := seconds % 60
 
 	if remainingSeconds == 0 {
-		return fmt.Sprintf("⏰ 请等待 %d 分钟后再发送消息", minutes)
+		return rl.catalog.T(locale, "ratelimit.minutes", minutes)
 	}
 
-	return fmt.Sprintf("⏰ 请等待 %d 分 %d 秒后再发送消息", minutes, remainingSeconds)
+	return rl.catalog.T(locale, "ratelimit.minutes_seconds", minutes, remainingSeconds)
 }
 
 // IsEnabled returns true if rate limiting is enabled
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"telegram-communication-bot/internal/i18n"
+	dbmodels "telegram-communication-bot/internal/models"
+	"time"
+
+	tgbot "github.com/go-telegram/bot"
+)
+
+// ReconcileReport describes what a reconciliation run found and repaired.
+type ReconcileReport struct {
+	CheckedTopics    int
+	MissingTopics    []int64         // users whose topic no longer exists
+	BannedUsers      []int64         // users banned by the delete-topic policy
+	DuplicateThreads map[int][]int64 // thread ID -> users sharing it
+	FixedDuplicates  []int64         // users detached from a thread owned by someone else
+	RestoredStatuses []int           // threads that were missing a ForumStatus row
+	OrphanedStatuses []int           // ForumStatus rows without an owning user (deleted)
+	ProbeErrors      int
+}
+
+// HasIssues reports whether the run found anything worth an admin's attention.
+func (r *ReconcileReport) HasIssues() bool {
+	return len(r.MissingTopics) > 0 || len(r.DuplicateThreads) > 0 ||
+		len(r.RestoredStatuses) > 0 || len(r.OrphanedStatuses) > 0 || r.ProbeErrors > 0
+}
+
+// Format renders the report for the admin group in the admin locale.
+func (r *ReconcileReport) Format(catalog *i18n.Catalog) string {
+	var text strings.Builder
+	text.WriteString(catalog.Admin("reconcile.report_title"))
+	text.WriteString(catalog.Admin("reconcile.report_checked", r.CheckedTopics))
+	text.WriteString(catalog.Admin("reconcile.report_missing", len(r.MissingTopics), formatIDs(r.MissingTopics)))
+	if len(r.BannedUsers) > 0 {
+		text.WriteString(catalog.Admin("reconcile.report_banned", len(r.BannedUsers), formatIDs(r.BannedUsers)))
+	}
+	text.WriteString(catalog.Admin("reconcile.report_duplicates", len(r.DuplicateThreads)))
+	for threadID, userIDs := range r.DuplicateThreads {
+		text.WriteString(catalog.Admin("reconcile.report_duplicate_item", threadID, formatIDs(userIDs)))
+	}
+	if len(r.FixedDuplicates) > 0 {
+		text.WriteString(catalog.Admin("reconcile.report_fixed", len(r.FixedDuplicates), formatIDs(r.FixedDuplicates)))
+	}
+	text.WriteString(catalog.Admin("reconcile.report_restored", len(r.RestoredStatuses)))
+	text.WriteString(catalog.Admin("reconcile.report_orphaned", len(r.OrphanedStatuses)))
+	if r.ProbeErrors > 0 {
+		text.WriteString(catalog.Admin("reconcile.report_errors", r.ProbeErrors))
+	}
+	return text.String()
+}
+
+func formatIDs(ids []int64) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("<code>%d</code>", id)
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// IsThreadNotFoundError reports whether a Telegram error means the forum topic is gone.
+func IsThreadNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "message thread not found") || strings.Contains(msg, "TOPIC_DELETED")
+}
+
+// ProbeForumTopic checks whether a topic still exists with an empty topic
+// edit, which changes nothing and shows nothing to agents. Returns false only
+// when Telegram reports the thread as missing.
+func (fs *ForumService) ProbeForumTopic(ctx context.Context, messageThreadID int) (bool, error) {
+	_, err := fs.bot.EditForumTopic(ctx, &tgbot.EditForumTopicParams{
+		ChatID:          fs.config.AdminGroupID,
+		MessageThreadID: messageThreadID,
+	})
+	if err == nil || strings.Contains(err.Error(), "TOPIC_NOT_MODIFIED") {
+		return true, nil
+	}
+	if IsThreadNotFoundError(err) || strings.Contains(err.Error(), "TOPIC_ID_INVALID") {
+		return false, nil
+	}
+	return true, err
+}
+
+// Reconcile verifies every user topic and ForumStatus row against the real
+// forum and repairs what can be repaired safely:
+//   - users whose topic is gone go through the delete-topic policy when
+//     DeleteTopicAsForeverBan is set, since Telegram sends no service message
+//     for a deleted topic; otherwise they are only reported
+//   - threads shared by several users stay with the user named in the topic
+//   - topics without a ForumStatus row get one
+//   - ForumStatus rows no user refers to are deleted
+func (fs *ForumService) Reconcile(ctx context.Context) (*ReconcileReport, error) {
+	if !fs.config.HasAdminGroup() {
+		return nil, fmt.Errorf("admin group not configured")
+	}
+
+	if !fs.reconcileMu.TryLock() {
+		return nil, fmt.Errorf("reconciliation already running")
+	}
+	defer fs.reconcileMu.Unlock()
+
+	report := &ReconcileReport{DuplicateThreads: make(map[int][]int64)}
+
+	users, err := fs.db.GetUsersWithThreads()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	threadUsers := make(map[int][]dbmodels.User)
+	for _, user := range users {
+		threadUsers[user.MessageThreadID] = append(threadUsers[user.MessageThreadID], user)
+	}
+
+	threadIDs := make([]int, 0, len(threadUsers))
+	for threadID := range threadUsers {
+		threadIDs = append(threadIDs, threadID)
+	}
+	sort.Ints(threadIDs)
+
+	for _, threadID := range threadIDs {
+		owners := threadUsers[threadID]
+		report.CheckedTopics++
+
+		exists, err := fs.ProbeForumTopic(ctx, threadID)
+		time.Sleep(50 * time.Millisecond)
+		if err != nil {
+			log.Printf("Error probing topic %d: %v", threadID, err)
+			report.ProbeErrors++
+			continue
+		}
+
+		if !exists {
+			for _, user := range owners {
+				report.MissingTopics = append(report.MissingTopics, user.UserID)
+				if !fs.config.DeleteTopicAsForeverBan {
+					continue
+				}
+				banned, err := fs.HandleTopicDeleted(user.UserID)
+				if err != nil {
+					log.Printf("Error handling deleted topic for user %d: %v", user.UserID, err)
+					continue
+				}
+				if banned {
+					report.BannedUsers = append(report.BannedUsers, user.UserID)
+				}
+			}
+			continue
+		}
+
+		status, statusErr := fs.db.GetForumStatus(threadID)
+
+		if len(owners) > 1 {
+			for _, user := range owners {
+				report.DuplicateThreads[threadID] = append(report.DuplicateThreads[threadID], user.UserID)
+			}
+			if statusErr == nil {
+				fs.resolveDuplicateThread(status, owners, report)
+			}
+		}
+
+		if statusErr != nil {
+			restored := &dbmodels.ForumStatus{
+				MessageThreadID: threadID,
+				Status:          "opened",
+			}
+			if err := fs.db.CreateOrUpdateForumStatus(restored); err != nil {
+				log.Printf("Error restoring forum status for thread %d: %v", threadID, err)
+				continue
+			}
+			report.RestoredStatuses = append(report.RestoredStatuses, threadID)
+		}
+	}
+
+	statuses, err := fs.db.GetAllForumStatuses()
+	if err != nil {
+		return report, fmt.Errorf("failed to load forum statuses: %w", err)
+	}
+
+	for _, status := range statuses {
+		if _, err := fs.GetUserByThreadID(status.MessageThreadID); err == nil {
+			continue
+		}
+		if err := fs.db.DeleteForumStatus(status.MessageThreadID); err != nil {
+			log.Printf("Error deleting orphaned forum status %d: %v", status.MessageThreadID, err)
+			continue
+		}
+		report.OrphanedStatuses = append(report.OrphanedStatuses, status.MessageThreadID)
+	}
+
+	log.Printf("Reconciliation finished: checked=%d missing=%d duplicates=%d restored=%d orphaned=%d errors=%d",
+		report.CheckedTopics, len(report.MissingTopics), len(report.DuplicateThreads),
+		len(report.RestoredStatuses), len(report.OrphanedStatuses), report.ProbeErrors)
+
+	return report, nil
+}
+
+// resolveDuplicateThread detaches every user but the topic's real owner, which
+// is read from the "name|user_id" topic name. If the owner cannot be
+// determined the assignment is only reported.
+func (fs *ForumService) resolveDuplicateThread(status *dbmodels.ForumStatus, owners []dbmodels.User, report *ReconcileReport) {
+	i := strings.LastIndex(status.Name, "|")
+	if i == -1 {
+		return
+	}
+	ownerID, err := strconv.ParseInt(status.Name[i+1:], 10, 64)
+	if err != nil {
+		return
+	}
+
+	found := false
+	for _, user := range owners {
+		if user.UserID == ownerID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	for _, user := range owners {
+		if user.UserID == ownerID {
+			continue
+		}
+		if err := fs.db.ClearUserThread(user.UserID); err != nil {
+			log.Printf("Error detaching user %d from thread %d: %v", user.UserID, status.MessageThreadID, err)
+			continue
+		}
+		report.FixedDuplicates = append(report.FixedDuplicates, user.UserID)
+	}
+}
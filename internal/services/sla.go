@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"telegram-communication-bot/internal/config"
+	"telegram-communication-bot/internal/database"
+	"telegram-communication-bot/internal/i18n"
+	dbmodels "telegram-communication-bot/internal/models"
+	"time"
+
+	tgbot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// SLAService tracks response times and alerts when users wait too long.
+type SLAService struct {
+	bot     *tgbot.Bot
+	config  *config.Config
+	db      *database.DB
+	catalog *i18n.Catalog
+}
+
+func NewSLAService(bot *tgbot.Bot, config *config.Config, db *database.DB, catalog *i18n.Catalog) *SLAService {
+	return &SLAService{
+		bot:     bot,
+		config:  config,
+		db:      db,
+		catalog: catalog,
+	}
+}
+
+// IsEnabled returns true if at least one SLA threshold is configured
+func (ss *SLAService) IsEnabled() bool {
+	return ss.config.SLAFirstResponseMinutes > 0 || ss.config.SLANextResponseMinutes > 0
+}
+
+func (ss *SLAService) getOrNew(userID int64) *dbmodels.ConversationSLA {
+	sla, err := ss.db.GetConversationSLA(userID)
+	if err != nil {
+		return &dbmodels.ConversationSLA{UserID: userID}
+	}
+	return sla
+}
+
+// RecordInbound starts the wait timer when a user writes and nobody owes
+// them a reply yet.
+func (ss *SLAService) RecordInbound(userID int64) error {
+	sla := ss.getOrNew(userID)
+	if !sla.AwaitingSince.IsZero() {
+		return nil
+	}
+	sla.AwaitingSince = time.Now()
+	sla.Alerted = false
+	return ss.db.SaveConversationSLA(sla)
+}
+
+// RecordResponse stops the wait timer when an agent replies and stores the
+// measured first or next response time.
+func (ss *SLAService) RecordResponse(userID int64, agentID int64) error {
+	sla := ss.getOrNew(userID)
+	if sla.AwaitingSince.IsZero() {
+		return nil
+	}
+
+	kind := dbmodels.ResponseKindNext
+	if !sla.FirstResponded {
+		kind = dbmodels.ResponseKindFirst
+	}
+
+	now := time.Now()
+	rt := &dbmodels.ResponseTime{
+		UserID:      userID,
+		AgentID:     agentID,
+		Kind:        kind,
+		WaitSeconds: int(now.Sub(sla.AwaitingSince).Seconds()),
+		RespondedAt: now,
+	}
+	if err := ss.db.CreateResponseTime(rt); err != nil {
+		return fmt.Errorf("failed to record response time: %w", err)
+	}
+
+	sla.AwaitingSince = time.Time{}
+	sla.FirstResponded = true
+	sla.Alerted = false
+	return ss.db.SaveConversationSLA(sla)
+}
+
+// ResetConversation starts a new conversation for the user, so the next
+// agent reply counts as a first response again.
+func (ss *SLAService) ResetConversation(userID int64) error {
+	sla := ss.getOrNew(userID)
+	sla.AwaitingSince = time.Time{}
+	sla.FirstResponded = false
+	sla.Alerted = false
+	return ss.db.SaveConversationSLA(sla)
+}
+
+// CheckBreaches posts an escalation alert for every conversation that has
+// waited longer than its SLA threshold. Each wait is alerted once.
+func (ss *SLAService) CheckBreaches(ctx context.Context) {
+	if ss.config.SLAFirstResponseMinutes > 0 {
+		ss.checkBreaches(ctx, false, ss.config.SLAFirstResponseMinutes)
+	}
+	if ss.config.SLANextResponseMinutes > 0 {
+		ss.checkBreaches(ctx, true, ss.config.SLANextResponseMinutes)
+	}
+}
+
+func (ss *SLAService) checkBreaches(ctx context.Context, firstResponded bool, thresholdMinutes int) {
+	cutoff := time.Now().Add(-time.Duration(thresholdMinutes) * time.Minute)
+	slas, err := ss.db.GetWaitingConversations(firstResponded, cutoff)
+	if err != nil {
+		log.Printf("Error loading waiting conversations: %v", err)
+		return
+	}
+
+	for i := range slas {
+		sla := &slas[i]
+		if ss.db.IsUserBanned(sla.UserID) {
+			continue
+		}
+
+		user, err := ss.db.GetUser(sla.UserID)
+		if err != nil {
+			continue
+		}
+
+		if err := ss.sendAlert(ctx, user, sla, firstResponded); err != nil {
+			log.Printf("Error sending SLA alert for user %d: %v", sla.UserID, err)
+			continue
+		}
+
+		sla.Alerted = true
+		if err := ss.db.SaveConversationSLA(sla); err != nil {
+			log.Printf("Error marking SLA alert for user %d: %v", sla.UserID, err)
+		}
+	}
+}
+
+func (ss *SLAService) sendAlert(ctx context.Context, user *dbmodels.User, sla *dbmodels.ConversationSLA, firstResponded bool) error {
+	kind := ss.catalog.Admin("sla.first_response")
+	if firstResponded {
+		kind = ss.catalog.Admin("sla.next_response")
+	}
+
+	waited := int(time.Since(sla.AwaitingSince).Minutes())
+
+	var text strings.Builder
+	text.WriteString(ss.catalog.Admin("sla.alert_title", kind))
+	text.WriteString(ss.catalog.Admin("sla.alert_waiting",
+		html.EscapeString(strings.TrimSpace(user.FirstName+" "+user.LastName)), user.UserID, waited))
+
+	if user.AssignedAgentID != 0 {
+		if agent, err := ss.db.GetAgent(user.AssignedAgentID); err == nil {
+			text.WriteString(ss.catalog.Admin("sla.alert_agent",
+				agent.AgentID, html.EscapeString(AgentDisplayName(agent))))
+		}
+	}
+
+	if user.MessageThreadID != 0 {
+		text.WriteString(ss.catalog.Admin("sla.alert_open", MessageLink(ss.config.AdminGroupID, user.MessageThreadID, 0)))
+	}
+
+	_, err := ss.bot.SendMessage(ctx, &tgbot.SendMessageParams{
+		ChatID:             ss.config.SLAAlertChatID,
+		Text:               text.String(),
+		ParseMode:          models.ParseModeHTML,
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: tgbot.True()},
+	})
+	return err
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"telegram-communication-bot/internal/database"
+	"telegram-communication-bot/internal/i18n"
+	dbmodels "telegram-communication-bot/internal/models"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot/models"
+)
+
+type TranscriptService struct {
+	db            *database.DB
+	retentionDays int
+	catalog       *i18n.Catalog
+}
+
+func NewTranscriptService(db *database.DB, retentionDays int, catalog *i18n.Catalog) *TranscriptService {
+	return &TranscriptService{
+		db:            db,
+		retentionDays: retentionDays,
+		catalog:       catalog,
+	}
+}
+
+// RecordMessage stores the content of a relayed message.
+// userChatMessageID and groupChatMessageID identify the message on both sides;
+// either may be 0 when the counterpart is unknown (e.g. media groups).
+func (ts *TranscriptService) RecordMessage(direction string, userID int64, message *models.Message, userChatMessageID int, groupChatMessageID int, threadID int) error {
+	entities := message.Entities
+	text := message.Text
+	if text == "" {
+		text = message.Caption
+		entities = message.CaptionEntities
+	}
+
+	var entitiesJSON string
+	if len(entities) > 0 {
+		if data, err := json.Marshal(entities); err == nil {
+			entitiesJSON = string(data)
+		}
+	}
+
+	mediaType, fileID, fileUniqueID := DescribeMedia(message)
+
+	record := &dbmodels.TranscriptMessage{
+		UserID:             userID,
+		Direction:          direction,
+		Text:               text,
+		Entities:           entitiesJSON,
+		MediaType:          mediaType,
+		FileID:             fileID,
+		FileUniqueID:       fileUniqueID,
+		UserChatMessageID:  userChatMessageID,
+		GroupChatMessageID: groupChatMessageID,
+		MessageThreadID:    threadID,
+		SentAt:             time.Now(),
+	}
+
+	if message.Date != 0 {
+		record.SentAt = time.Unix(int64(message.Date), 0)
+	}
+
+	if message.From != nil {
+		record.SenderID = message.From.ID
+		record.SenderName = strings.TrimSpace(message.From.FirstName + " " + message.From.LastName)
+	}
+
+	return ts.db.CreateTranscriptMessage(record)
+}
+
+// GetTranscript returns a user's stored conversation in chronological order.
+func (ts *TranscriptService) GetTranscript(userID int64) ([]dbmodels.TranscriptMessage, error) {
+	return ts.db.GetTranscript(userID)
+}
+
+// CleanupExpired deletes transcript entries older than the retention period.
+// A retention of 0 keeps transcripts forever.
+func (ts *TranscriptService) CleanupExpired() error {
+	if ts.retentionDays <= 0 {
+		return nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -ts.retentionDays)
+	return ts.db.CleanupOldTranscriptMessages(cutoff)
+}
+
+// DescribeMedia returns the media type, file ID and unique file ID of a message.
+// All values are empty for plain text messages.
+func DescribeMedia(message *models.Message) (string, string, string) {
+	switch {
+	case len(message.Photo) > 0:
+		largest := message.Photo[len(message.Photo)-1]
+		return "photo", largest.FileID, largest.FileUniqueID
+	// Animations also carry a Document, so they must be matched first.
+	case message.Animation != nil:
+		return "animation", message.Animation.FileID, message.Animation.FileUniqueID
+	case message.Document != nil:
+		return "document", message.Document.FileID, message.Document.FileUniqueID
+	case message.Video != nil:
+		return "video", message.Video.FileID, message.Video.FileUniqueID
+	case message.Audio != nil:
+		return "audio", message.Audio.FileID, message.Audio.FileUniqueID
+	case message.Voice != nil:
+		return "voice", message.Voice.FileID, message.Voice.FileUniqueID
+	case message.VideoNote != nil:
+		return "video_note", message.VideoNote.FileID, message.VideoNote.FileUniqueID
+	case message.Sticker != nil:
+		return "sticker", message.Sticker.FileID, message.Sticker.FileUniqueID
+	case message.Location != nil:
+		return "location", "", ""
+	case message.Contact != nil:
+		return "contact", "", ""
+	}
+	return "", "", ""
+}
+
+// Search finds transcript messages containing every term of the query.
+// Terms shorter than three characters cannot use the trigram index, so such
+// queries fall back to an unranked substring scan.
+func (ts *TranscriptService) Search(query string, filter database.TranscriptSearchFilter) ([]database.TranscriptSearchResult, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < 3 {
+			return ts.db.SearchTranscriptsLike(terms, filter)
+		}
+	}
+
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return ts.db.SearchTranscripts(strings.Join(quoted, " "), filter)
+}
+
+// MessageLink returns a t.me deep link to a message in a private supergroup
+// topic. If messageID is 0 the link points at the topic itself.
+func MessageLink(chatID int64, threadID int, messageID int) string {
+	internalID := strings.TrimPrefix(strconv.FormatInt(chatID, 10), "-100")
+	if messageID == 0 {
+		return fmt.Sprintf("https://t.me/c/%s/%d", internalID, threadID)
+	}
+	return fmt.Sprintf("https://t.me/c/%s/%d/%d", internalID, threadID, messageID)
+}